@@ -0,0 +1,66 @@
+package mapper
+
+import (
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// WithAdvanceFilter 在比较轮廓之前先比较字形的前进宽度和左侧间距，任意一项相差超过 maxDiff 的候选直接跳过。
+// 标准字体有上万个候选时，大部分字形仅凭 hmtx 中的度量就可以排除。maxDiff 的单位与 SetFlatness 相同
+// （1000 单位 em 下的字体单位），小于等于 0 时关闭。混淆时改动了字形间距的字体不应开启
+func WithAdvanceFilter(maxDiff float64) Option {
+	return func(g *GlyphOutlineMapper) {
+		g.advanceFilter = max(maxDiff, 0)
+	}
+}
+
+// hmetricsSource 是能够读取字形水平度量的字体
+type hmetricsSource interface {
+	// HMetrics 返回字形的前进宽度和左侧间距，单位是字体单位
+	HMetrics(index int) (advance, lsb int, err error)
+}
+
+func (s truetypeSource) HMetrics(index int) (advance, lsb int, err error) {
+	h := s.font.HMetric(fixed.Int26_6(s.font.FUnitsPerEm()), truetype.Index(index))
+	return int(h.AdvanceWidth), int(h.LeftSideBearing), nil
+}
+
+// HMetrics CFF 字体的左侧间距取字形边界框的左边
+func (s cffSource) HMetrics(index int) (advance, lsb int, err error) {
+	bounds, adv, err := s.font.GlyphBounds(nil, sfnt.GlyphIndex(index), fixed.Int26_6(s.font.UnitsPerEm()), font.HintingNone)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(adv), int(bounds.Min.X), nil
+}
+
+// hmetrics 是以 em 为单位的字形水平度量
+type hmetrics struct {
+	advance, lsb float64
+}
+
+// setHMetrics 在开启 WithAdvanceFilter 时读取字形的水平度量，读取失败的字形不参与过滤
+func (g *GlyphOutlineMapper) setHMetrics(glyph *cachedGlyph, f glyphSource, index int) {
+	source, ok := f.(hmetricsSource)
+	if g.advanceFilter <= 0 || !ok {
+		return
+	}
+	advance, lsb, err := source.HMetrics(index)
+	if err != nil {
+		return
+	}
+	upem := float64(f.UnitsPerEm())
+	glyph.hmetrics = &hmetrics{advance: float64(advance) / upem, lsb: float64(lsb) / upem}
+}
+
+// hmetricsClose 判断两个字形的水平度量是否在 WithAdvanceFilter 的范围之内，没有度量时总是返回 true
+func (g *GlyphOutlineMapper) hmetricsClose(special, standard *cachedGlyph) bool {
+	a, b := special.hmetrics, standard.hmetrics
+	if a == nil || b == nil {
+		return true
+	}
+	limit := g.advanceFilter / 1000
+	return abs(a.advance-b.advance) <= limit && abs(a.lsb-b.lsb) <= limit
+}
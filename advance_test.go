@@ -0,0 +1,44 @@
+package mapper
+
+import "testing"
+
+func TestWithAdvanceFilter(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	// 'A' 的轮廓相同但前进宽度不同，'B' 的度量和轮廓都与特殊字形一致
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 900},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 805},
+	}, map[rune]rune{'A': 1, 'B': 2})
+
+	tests := []struct {
+		maxDiff float64
+		want    rune
+	}{
+		{0, 'A'},
+		{10, 'B'},
+		{200, 'A'},
+	}
+	for _, tt := range tests {
+		metrics := &countingMetrics{}
+		mapper, err := NewGlyphOutlineMapper(special, standard, WithAdvanceFilter(tt.maxDiff), WithMetrics(metrics))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, r, ok := mapper.MappingRune(0xE000); !ok || r != tt.want {
+			t.Errorf("maxDiff %v: got %q (ok=%v), want %q", tt.maxDiff, r, ok, tt.want)
+		}
+		if tt.maxDiff == 10 && metrics.compared != 1 {
+			t.Errorf("maxDiff %v: compared %d candidates, want 1", tt.maxDiff, metrics.compared)
+		}
+	}
+
+	f, err := parseFont(standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if advance, lsb, err := f.source.(hmetricsSource).HMetrics(2); err != nil || advance != 805 || lsb != 100 {
+		t.Errorf("HMetrics = %d, %d, %v; want 805, 100", advance, lsb, err)
+	}
+}
@@ -0,0 +1,61 @@
+package mapper
+
+import (
+	"context"
+	"fmt"
+)
+
+// BatchMapper 用同一个标准字体映射多个特殊字体。标准字体只解析一次，
+// 标准字形的缓存也只建立一次，由它创建的所有 mapper 共享
+type BatchMapper struct {
+	standard *parsedFont
+	lastRune rune
+	opts     []Option
+	base     *GlyphOutlineMapper // 只用来建立共享的标准字形缓存
+}
+
+// NewBatchMapper 解析标准字体，opts 会应用到之后创建的每个 mapper 上
+func NewBatchMapper(standardFontData []byte, opts ...Option) (*BatchMapper, error) {
+	standard, err := parseFont(standardFontData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrStandardFontParse, err)
+	}
+	b := &BatchMapper{standard: standard, lastRune: findLastRune(standard.source), opts: opts}
+	b.base = newGlyphOutlineMapperWithLastRune(standard.source, standard.source, b.lastRune, opts...)
+	// 没有校验和时 WithStandardIndex 设置的索引会被忽略
+	b.base.standardChecksum = standard.checksum
+	return b, nil
+}
+
+// Mapper 为一个特殊字体创建 mapper，返回的 mapper 与其他 mapper 共享标准字形的缓存
+func (b *BatchMapper) Mapper(specialFontData []byte) (*GlyphOutlineMapper, error) {
+	special, err := parseFont(specialFontData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSpecialFontParse, err)
+	}
+	if sameGlyphs(special, b.standard) {
+		return nil, ErrIdenticalFonts
+	}
+	cache, err := b.base.standardGlyphs(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	g := newGlyphOutlineMapperWithLastRune(special.source, b.standard.source, b.lastRune, b.opts...)
+	g.specialChecksum, g.standardChecksum = special.checksum, b.standard.checksum
+	g.setCache(cache.share())
+	return g, nil
+}
+
+// Mapping 依次映射每个特殊字体中 [start, end] 内的字符，结果与 specialFonts 一一对应。
+// 任意一个特殊字体解析失败时返回错误，错误中标明是第几个字体
+func (b *BatchMapper) Mapping(specialFonts [][]byte, start, end rune) ([]Mapping, error) {
+	results := make([]Mapping, len(specialFonts))
+	for i, data := range specialFonts {
+		g, err := b.Mapper(data)
+		if err != nil {
+			return nil, fmt.Errorf("special font %d: %w", i, err)
+		}
+		results[i] = g.Mapping(start, end)
+	}
+	return results, nil
+}
@@ -0,0 +1,106 @@
+package mapper
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBatchMapper(t *testing.T) {
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+	// 两个特殊字体用不同的码位编码同样的字形
+	special1 := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+	special2 := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{0xE001: 1, 0xE000: 2})
+
+	batch, err := NewBatchMapper(standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := batch.Mapping([][]byte{special1, special2}, 0xE000, 0xE001)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0][0xE000] != 'B' || got[0][0xE001] != 'A' || got[1][0xE000] != 'A' || got[1][0xE001] != 'B' {
+		t.Fatalf("got %v", got)
+	}
+	if batch.base.cache == nil || len(batch.base.cache.glyphs) != 2 {
+		t.Error("standard glyphs should be cached once on the batch")
+	}
+
+	if _, err := batch.Mapping([][]byte{special1, standard}, 0xE000, 0xE001); !errors.Is(err, ErrIdenticalFonts) {
+		t.Errorf("error = %v, want ErrIdenticalFonts", err)
+	}
+}
+
+func TestBatchMapper_SharesIndexes(t *testing.T) {
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+
+	batch, err := NewBatchMapper(standard, WithOutlineHash(), WithNearestCandidates(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := batch.Mapper(special)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.cache.byOutlineHash) != 2 || g.cache.featureTree == nil {
+		t.Fatal("batch mapper lost the indexes of the shared standard cache")
+	}
+	if got := g.Mapping(0xE000, 0xE001); len(got) != 2 || got[0xE000] != 'B' || got[0xE001] != 'A' {
+		t.Errorf("got %v", got)
+	}
+	if got := g.MapString("\ue000"); got != "B" {
+		t.Errorf("MapString = %q, want B", got)
+	}
+	other, err := batch.Mapper(special)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := other.cache.decoded.Load(rune(0xE000)); ok {
+		t.Error("decoded runes should not be shared between batch mappers")
+	}
+}
+
+func TestBatchMapper_StandardIndex(t *testing.T) {
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+
+	index, err := BuildStandardIndex(standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 去掉索引中的 'A' 后 U+E001 找不到匹配，说明字形来自索引而不是字体
+	delete(index.glyphs, 'A')
+	batch, err := NewBatchMapper(standard, WithStandardIndex(index))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := batch.Mapper(special)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.Mapping(0xE000, 0xE001); len(got) != 1 || got[0xE000] != 'B' {
+		t.Errorf("got %v, want only U+E000 => 'B' from the index", got)
+	}
+}
@@ -0,0 +1,254 @@
+package mapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/math/fixed"
+)
+
+// cachedGlyph 是已经加载好的字形，开启形状签名比较时同时保存其签名
+type cachedGlyph struct {
+	r           rune
+	font        string // 字形所在字体的名字
+	outline     *outline
+	signature   []contourSignature
+	points      []vec                // 浮点坐标模式下以 em 为单位的轮廓点
+	hash        uint64               // 感知哈希模式下的字形哈希
+	glyfHash    glyfHash             // 标准字形原始数据的哈希，见 rawMatch
+	outlineHash uint64               // 开启 WithOutlineHash 时轮廓规范化序列的哈希
+	bounds      [4]fixed.Int26_6     // 轮廓的边界框，见 compareOutlinePipeline
+	hmetrics    *hmetrics            // 开启 WithAdvanceFilter 时的水平度量
+	features    [featureDims]float64 // 开启 WithNearestCandidates 时的特征向量
+}
+
+// standardCache 缓存标准字体中所有存在字形的字符，先按字体的优先级、再按码位升序排列，
+// 同时缓存基于这些字形解码过的特殊字符
+type standardCache struct {
+	glyphs  []*cachedGlyph
+	byRune  map[rune]*cachedGlyph // 多个标准字体都有的字符只记录优先级最高的一个
+	errs    []*GlyphLoadError
+	decoded sync.Map // rune => decodedRune
+
+	hashIndex     []map[uint16][]int        // 感知哈希每一段 => glyphs 中的下标，见 indexHashes
+	lsh           *hashLSH                  // 感知哈希的近似索引，见 WithHashLSH
+	byGlyfHash    map[glyfHash]*cachedGlyph // 原始字形数据的哈希 => 标准字形，见 rawMatch
+	byOutlineHash map[uint64]*cachedGlyph   // 轮廓哈希 => 标准字形，见 hashedMatch
+	featureTree   *kdTree                   // 特征向量的 KD 树，见 candidates
+
+	encodeOnce sync.Once
+	encoding   Mapping // 标准字符 => 特殊字符，由 Encode 第一次调用时建立
+}
+
+// share 返回共享字形和全部索引的新缓存，解码结果与特殊字体有关，新缓存中的解码结果和 Encode 的反向映射为空
+func (c *standardCache) share() *standardCache {
+	return &standardCache{
+		glyphs:        c.glyphs,
+		byRune:        c.byRune,
+		errs:          c.errs,
+		hashIndex:     c.hashIndex,
+		lsh:           c.lsh,
+		byGlyfHash:    c.byGlyfHash,
+		byOutlineHash: c.byOutlineHash,
+		featureTree:   c.featureTree,
+	}
+}
+
+// Warm 预先加载标准字体中的全部字形（以及开启时的形状签名），
+// 之后的 MappingRune 不再需要承担建立缓存的开销。可以并发调用，重复调用不会重复加载
+func (g *GlyphOutlineMapper) Warm(ctx context.Context) error {
+	_, err := g.standardGlyphs(ctx)
+	return err
+}
+
+// resetCache 丢弃已经建立的标准字体缓存，在影响字形加载结果的配置变化后调用
+func (g *GlyphOutlineMapper) resetCache() {
+	g.cacheMu.Lock()
+	g.setCache(nil)
+	g.cacheMu.Unlock()
+}
+
+// setCache 替换标准字体的字形缓存，调用方需要持有 cacheMu，或者 mapper 还没有被其他 goroutine 使用
+func (g *GlyphOutlineMapper) setCache(cache *standardCache) {
+	g.cache = cache
+	g.builtCache.Store(cache)
+}
+
+// Close 释放 mapper 缓存的标准字体字形以及字体复用的资源。Close 之后 mapper 仍然可以使用，
+// 需要时会重新加载。不能与映射并发调用
+func (g *GlyphOutlineMapper) Close() error {
+	g.resetCache()
+	var errs []error
+	for _, f := range append(g.standardFonts(), namedFont{name: "special", source: g.specialFont}) {
+		if closer, ok := f.source.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("close %s font: %w", f.name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// standardGlyphs 返回标准字体的字形缓存，尚未建立时会先建立缓存
+func (g *GlyphOutlineMapper) standardGlyphs(ctx context.Context) (*standardCache, error) {
+	g.cacheMu.Lock()
+	defer g.cacheMu.Unlock()
+	if g.cache != nil {
+		return g.cache, nil
+	}
+	cache, err := g.buildStandardCache(ctx)
+	if err != nil {
+		return nil, err
+	}
+	g.setCache(cache)
+	return cache, nil
+}
+
+func (g *GlyphOutlineMapper) buildStandardCache(ctx context.Context) (*standardCache, error) {
+	cache := &standardCache{byRune: map[rune]*cachedGlyph{}, byGlyfHash: map[glyfHash]*cachedGlyph{}, byOutlineHash: map[uint64]*cachedGlyph{}}
+	scanned := 0
+	index := g.usableStandardIndex()
+	var indexHashes map[rune]uint64
+	if index != nil {
+		indexHashes = g.outlineHashes(index)
+	}
+	for _, f := range g.standardFonts() {
+		loaded := map[rune]bool{}
+		for r := range g.candidateRunes() {
+			if scanned++; scanned%1024 == 1 {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+			}
+			if loaded[r] || g.ignored(r) {
+				continue
+			}
+			if index != nil && f.name == "standard" {
+				if o, ok := index.glyphs[r]; ok {
+					glyph := g.newCachedGlyph(f.source, f.name, r, o, indexHashes[r])
+					g.setHMetrics(glyph, f.source, f.source.Index(r))
+					if g.rawGlyphMatch {
+						cache.indexRawGlyph(f.source, glyph)
+					}
+					cache.glyphs = append(cache.glyphs, glyph)
+					loaded[r] = true
+					cache.byRune[r] = glyph
+				}
+				continue
+			}
+			has, err := f.source.Has(r)
+			if err != nil {
+				g.metrics.AddError()
+				cache.errs = append(cache.errs, &GlyphLoadError{Font: f.name, Rune: r, Index: truetype.Index(f.source.Index(r)), Err: err})
+				continue
+			}
+			if !has {
+				continue
+			}
+			glyph, loadErr := g.loadCachedGlyph(f.source, f.name, r)
+			if loadErr != nil {
+				g.metrics.AddError()
+				cache.errs = append(cache.errs, loadErr)
+				continue
+			}
+			if g.rawGlyphMatch {
+				cache.indexRawGlyph(f.source, glyph)
+			}
+			cache.glyphs = append(cache.glyphs, glyph)
+			loaded[r] = true
+			if _, ok := cache.byRune[r]; !ok {
+				cache.byRune[r] = glyph
+			}
+		}
+	}
+	if g.perceptualHash && g.hashDistance < hashBands {
+		cache.indexHashes()
+	} else if g.perceptualHash && g.lshTables > 0 {
+		cache.indexLSH(g.lshTables, g.lshBits)
+	}
+	for _, glyph := range cache.glyphs {
+		cache.indexOutlineHash(glyph)
+	}
+	if g.nearestK > 0 {
+		cache.indexFeatures()
+	}
+	return cache, nil
+}
+
+// loadCachedGlyph 加载字符对应的字形，name 用于在错误中标明是哪个字体
+func (g *GlyphOutlineMapper) loadCachedGlyph(f glyphSource, name string, r rune) (*cachedGlyph, *GlyphLoadError) {
+	return g.loadCachedGlyphIndex(f, name, r, f.Index(r))
+}
+
+// loadCachedGlyphIndex 与 loadCachedGlyph 相同，但直接按字形索引加载，r 只用于记录
+func (g *GlyphOutlineMapper) loadCachedGlyphIndex(f glyphSource, name string, r rune, index int) (*cachedGlyph, *GlyphLoadError) {
+	o, err := g.loadGlyph(f, index, g.loadScale(f))
+	if err != nil {
+		return nil, &GlyphLoadError{Font: name, Rune: r, Index: truetype.Index(index), Err: err}
+	}
+	glyph := g.newCachedGlyph(f, name, r, o, 0)
+	g.setHMetrics(glyph, f, index)
+	return glyph, nil
+}
+
+// newCachedGlyph 为已经加载的轮廓计算开启的比较方式所需的数据，hash 是 StandardIndex 中保存的轮廓哈希，为 0 时重新计算
+func (g *GlyphOutlineMapper) newCachedGlyph(f glyphSource, name string, r rune, o *outline, hash uint64) *cachedGlyph {
+	o = g.normalizeOutline(o, g.loadScale(f))
+	glyph := &cachedGlyph{r: r, font: name, outline: o, bounds: outlineBounds(o)}
+	if g.shapeSignature {
+		glyph.signature = glyphSignature(o, g.flatnessFor(f))
+	}
+	if g.perceptualHash {
+		glyph.hash = glyphPHash(o)
+	}
+	if g.outlineHashing && hash != 0 {
+		glyph.outlineHash = hash
+	} else if g.outlineHashing {
+		glyph.outlineHash = outlineHash(o, g.tolerance+1)
+	}
+	if g.nearestK > 0 {
+		glyph.features = glyphFeatures(glyph)
+	}
+	if g.floatCoordinates {
+		upem := float64(f.UnitsPerEm())
+		glyph.points = make([]vec, len(o.points))
+		for i, p := range o.points {
+			glyph.points[i] = vec{float64(p.X) / upem, float64(p.Y) / upem}
+		}
+	}
+	return glyph
+}
+
+// matchGlyphs 比较两个已加载的字形，返回是否匹配、相对于容差的偏差（0 表示完全一致）以及做出判断的比较方式
+func (g *GlyphOutlineMapper) matchGlyphs(special, standard *cachedGlyph) (bool, float64, matchStage) {
+	if g.matchThreshold > 0 {
+		deviation := g.candidateDeviation(special, standard)
+		return similarityScore(deviation) >= g.matchThreshold, deviation, stageThreshold
+	}
+	if len(g.matchers) > 0 {
+		matched, deviation := g.matchWithMatchers(special, standard)
+		return matched, deviation, stageMatchers
+	}
+	if g.perceptualHash {
+		matched, deviation := g.compareHashes(special, standard)
+		return matched, deviation, stagePerceptualHash
+	}
+	if g.shapeSignature {
+		similarity := signatureSimilarity(special.signature, standard.signature)
+		if similarity < g.signatureThreshold {
+			return false, math.Inf(1), stageSignature
+		}
+		return true, relativeDeviation(1-similarity, 1, 1-g.signatureThreshold), stageSignature
+	}
+	if g.floatCoordinates {
+		matched, deviation := g.compareFloatOutlines(special, standard)
+		return matched, deviation, stageOutline
+	}
+	matched, deviation := g.compareOutlinePipeline(special, standard, g.tolerance)
+	return matched, deviation, stageOutline
+}
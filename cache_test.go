@@ -0,0 +1,121 @@
+package mapper
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGlyphOutlineMapper_Warm(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := mapper.Warm(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Warm with cancelled context = %v, want context.Canceled", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := mapper.Warm(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := len(mapper.cache.glyphs); n != 2 {
+		t.Fatalf("cached %d standard glyphs, want 2", n)
+	}
+
+	if _, standardRune, ok := mapper.MappingRune(0xE000); !ok || standardRune != 'B' {
+		t.Fatalf("got %q (ok=%v), want 'B'", standardRune, ok)
+	}
+}
+
+func TestGlyphOutlineMapper_Close(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mapper.Mapping(0xE000, 0xE000); got[0xE000] != 'A' {
+		t.Fatalf("got %v, want U+E000 => 'A'", got)
+	}
+	if err := mapper.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if mapper.cache != nil {
+		t.Error("cache not released by Close")
+	}
+	if got := mapper.Mapping(0xE000, 0xE000); got[0xE000] != 'A' {
+		t.Errorf("after Close got %v, want U+E000 => 'A'", got)
+	}
+}
+
+func TestGlyphOutlineMapper_IdentityFastPath(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{'A': 1, 0xE000: 2})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, got, ok := mapper.MappingRune('A'); !ok || got != 'A' {
+		t.Fatalf("got %q (ok=%v), want 'A'", got, ok)
+	}
+	if mapper.cache != nil {
+		t.Error("identity match built the standard cache")
+	}
+	if _, got, ok := mapper.MappingRune(0xE000); !ok || got != 'B' {
+		t.Errorf("got %q (ok=%v), want 'B'", got, ok)
+	}
+	if mapper.cache == nil {
+		t.Error("standard cache not built for a scrambled rune")
+	}
+	// 其他 goroutine 正在建立缓存（持有 cacheMu）时，同码位的快速路径不需要等待
+	building, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	building.cacheMu.Lock()
+	done := make(chan rune, 1)
+	go func() {
+		_, got, _ := building.MappingRune('A')
+		done <- got
+	}()
+	select {
+	case got := <-done:
+		if got != 'A' {
+			t.Errorf("got %q while the cache was being built, want 'A'", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("identity match waited for the standard cache to be built")
+	}
+	building.cacheMu.Unlock()
+}
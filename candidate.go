@@ -0,0 +1,111 @@
+package mapper
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"math/bits"
+	"slices"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// Candidate 是特殊字符的一个候选标准字符
+type Candidate struct {
+	Standard     rune    // 候选的标准字符
+	StandardFont string  // 候选所在的标准字体
+	Score        float64 // 相似度得分，定义与 GlyphSimilarity 相同，但超出容差的候选也有得分
+	Match        bool    // 是否在容差之内，即 Mapping 会不会接受这个候选
+}
+
+// MappingRuneCandidates 返回与特殊字符轮廓最相似的 n 个标准字符，按得分从高到低排列。
+// 与 MappingRune 不同，超出容差的候选也会返回，便于人工核对差一点就能匹配的字符；
+// 轮廓结构（轮廓数和点数）不同的字符无法计算偏差，不会出现在结果中
+func (g *GlyphOutlineMapper) MappingRuneCandidates(unicode rune, n int) []Candidate {
+	if n <= 0 {
+		return nil
+	}
+	if has, err := g.specialFont.Has(unicode); err != nil || !has {
+		return nil
+	}
+	special, loadErr := g.loadCachedGlyph(g.specialFont, "special", unicode)
+	if loadErr != nil {
+		return nil
+	}
+	cache, err := g.standardGlyphs(context.Background())
+	if err != nil {
+		return nil
+	}
+
+	return g.topCandidates(special, cache.glyphs, n, nil)
+}
+
+// topCandidates 返回 standards 中与 special 最相似的 n 个候选，按得分从高到低排列，skip 为 true 的候选不参与排序
+func (g *GlyphOutlineMapper) topCandidates(special *cachedGlyph, standards []*cachedGlyph, n int, skip func(*cachedGlyph) bool) []Candidate {
+	var candidates []Candidate
+	for _, standard := range standards {
+		if skip != nil && skip(standard) {
+			continue
+		}
+		deviation := g.candidateDeviation(special, standard)
+		if math.IsInf(deviation, 1) {
+			continue
+		}
+		matched, _, _ := g.matchGlyphs(special, standard)
+		candidates = append(candidates, Candidate{
+			Standard:     standard.r,
+			StandardFont: standard.font,
+			Score:        similarityScore(deviation),
+			Match:        matched,
+		})
+	}
+	slices.SortStableFunc(candidates, func(a, b Candidate) int { return cmp.Compare(b.Score, a.Score) })
+	return candidates[:min(n, len(candidates))]
+}
+
+// WithAlternatives 让每个匹配结果在 MappingResult.Alternatives 中带上除了选中的字符之外得分最高的 k 个候选，
+// 超出容差的候选也包括在内，供人工复核或下游的 OCR 校验使用。开启后跳过同码位和哈希等快速路径，每个字符都会扫描全部候选
+func WithAlternatives(k int) Option {
+	return func(g *GlyphOutlineMapper) {
+		g.alternatives = max(k, 0)
+	}
+}
+
+// candidateDeviation 与 matchGlyphs 返回的偏差含义相同，但超出容差时不会截断为 +Inf，
+// 只有轮廓结构不同、无法逐点比较时才返回 +Inf。使用 WithMatchers 时偏差由 matcher 决定，不匹配的候选不会出现
+func (g *GlyphOutlineMapper) candidateDeviation(special, standard *cachedGlyph) float64 {
+	if len(g.matchers) > 0 {
+		_, deviation := g.matchWithMatchers(special, standard)
+		return deviation
+	}
+	if g.perceptualHash {
+		return relativeDeviation(float64(bits.OnesCount64(special.hash^standard.hash)), 1, float64(g.hashDistance))
+	}
+	if g.shapeSignature {
+		similarity := signatureSimilarity(special.signature, standard.signature)
+		return relativeDeviation(1-similarity, 1, 1-g.signatureThreshold)
+	}
+	a, b := special.outline, standard.outline
+	if g.floatCoordinates {
+		total, ok := g.floatDeviation(special, standard, math.Inf(1))
+		if !ok {
+			return math.Inf(1)
+		}
+		return relativeDeviation(total, float64(len(special.points)), g.floatTolerance)
+	}
+	if len(a.ends) != len(b.ends) || len(a.points) != len(b.points) {
+		return math.Inf(1)
+	}
+	// 不限制单个点的偏差，轮廓和起点的对应方式与 compareOutlinePipeline 相同，再把偏差换算回相对于 g.tolerance
+	ok, deviation := g.pairOutlines(a, b, unboundedTolerance, true)
+	if !ok {
+		return math.Inf(1)
+	}
+	if deviation == 0 {
+		return 0
+	}
+	return deviation * float64(unboundedTolerance) / float64(g.tolerance)
+}
+
+// unboundedTolerance 是计算不截断的偏差时使用的容差，任意两个点的偏差都不会超过它
+const unboundedTolerance = fixed.Int26_6(math.MaxInt32)
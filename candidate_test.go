@@ -0,0 +1,61 @@
+package mapper
+
+import "testing"
+
+func TestGlyphOutlineMapper_MappingRuneCandidates(t *testing.T) {
+	special := buildTestFont(16384, []testGlyph{
+		{contours: [][]testPoint{square(1000, 1000, 8000)}, advance: 10000},
+	}, map[rune]rune{0xE000: 1})
+	// 'A' 在容差之内，'B' 超出容差但结构相同，'C' 结构不同
+	standard := buildTestFont(16384, []testGlyph{
+		{contours: [][]testPoint{square(1000, 1000, 8001)}, advance: 10000},
+		{contours: [][]testPoint{square(1000, 1000, 8100)}, advance: 10000},
+		{contours: [][]testPoint{triangle(1000, 1000, 8000)}, advance: 10000},
+	}, map[rune]rune{'A': 1, 'B': 2, 'C': 3})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := mapper.MappingRuneCandidates(0xE000, 5)
+	if len(got) != 2 {
+		t.Fatalf("got %+v, want 'A' and 'B'", got)
+	}
+	if got[0].Standard != 'A' || !got[0].Match || got[0].Score <= 0.5 {
+		t.Errorf("first candidate %+v, want matching 'A' with score above 0.5", got[0])
+	}
+	if got[1].Standard != 'B' || got[1].Match || got[1].Score <= 0 || got[1].Score >= 0.5 {
+		t.Errorf("second candidate %+v, want near-miss 'B' with score in (0, 0.5)", got[1])
+	}
+	if got := mapper.MappingRuneCandidates(0xE000, 1); len(got) != 1 || got[0].Standard != 'A' {
+		t.Errorf("top-1 = %+v, want 'A'", got)
+	}
+}
+
+func TestWithAlternatives(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 504)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 502)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2, 'C': 3, 'D': 4})
+
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithAlternatives(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, ok := mapper.MappingRuneResult(0xE000)
+	if !ok || result.Standard != 'B' {
+		t.Fatalf("got %+v (ok=%v), want 'B'", result, ok)
+	}
+	// 'C' 的轮廓结构不同，不会出现在候选中
+	if len(result.Alternatives) != 2 || result.Alternatives[0].Standard != 'D' || result.Alternatives[1].Standard != 'A' {
+		t.Errorf("alternatives = %+v, want D then A", result.Alternatives)
+	}
+	if results := mapper.MappingDetailed(0xE000, 0xE000); len(results) != 1 || len(results[0].Alternatives) != 2 {
+		t.Errorf("MappingDetailed = %+v, want two alternatives", results)
+	}
+}
@@ -0,0 +1,159 @@
+package mapper
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+	"sync"
+)
+
+// Checkpoint 是一次范围映射的进度，由 WithCheckpoint 设置的 CheckpointStore 定期保存
+type Checkpoint struct {
+	SpecialChecksum  string          // 特殊字体原始数据的 SHA-256，字体变化后进度作废
+	StandardChecksum string          // 标准字体原始数据的 SHA-256
+	Start, End       rune            // 映射的范围
+	Next             rune            // [Start, Next) 内的字符都已经比较完
+	Results          []MappingResult // 目前为止找到的结果，按特殊字符升序排列
+}
+
+// CheckpointStore 保存和读取映射进度，实现需要可以并发调用
+type CheckpointStore interface {
+	// Load 读取最近一次保存的进度，从来没有保存过时返回 nil, nil
+	Load() (*Checkpoint, error)
+	// Save 保存进度，覆盖之前保存的内容
+	Save(cp *Checkpoint) error
+}
+
+// WithCheckpoint 让 Mapping 等按范围映射的方法每比较完 interval 个字符就把进度保存到 store，
+// 结束或被取消时也会保存一次。再次映射同一个范围时会从保存的进度继续，已经比较过的字符不会重新比较；
+// 字体或范围不同时忽略保存的进度。interval 不大于 0 时只在结束时保存。
+// 读写进度失败不会中断映射，MappingContext 会返回该错误
+func WithCheckpoint(store CheckpointStore, interval int) Option {
+	return func(g *GlyphOutlineMapper) {
+		g.checkpointStore = store
+		g.checkpointInterval = interval
+	}
+}
+
+// FileCheckpointStore 返回以 JSON 把进度保存在 path 的 CheckpointStore。
+// 写入时先写临时文件再重命名，中途崩溃不会留下写了一半的文件
+func FileCheckpointStore(path string) CheckpointStore {
+	return fileCheckpointStore{path: path}
+}
+
+type fileCheckpointStore struct {
+	path string
+}
+
+func (s fileCheckpointStore) Load() (*Checkpoint, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+func (s fileCheckpointStore) Save(cp *Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// checkpointer 跟踪一次范围映射的进度。字符按顺序开始比较、但完成的顺序不固定，
+// 因此 Next 取仍在比较中的最小字符，保证它之前的字符都已经完成
+type checkpointer struct {
+	store    CheckpointStore
+	interval int
+
+	mu         sync.Mutex
+	cp         Checkpoint
+	pending    map[rune]bool
+	dispatched rune // 最后一个开始比较的字符
+	completed  int
+	err        error
+}
+
+// newCheckpointer 读取保存的进度，没有设置 WithCheckpoint 时返回 nil
+func (g *GlyphOutlineMapper) newCheckpointer(start, end rune) *checkpointer {
+	if g.checkpointStore == nil {
+		return nil
+	}
+	c := &checkpointer{
+		store:    g.checkpointStore,
+		interval: g.checkpointInterval,
+		cp:       Checkpoint{SpecialChecksum: g.specialChecksum, StandardChecksum: g.standardChecksum, Start: start, End: end, Next: start},
+		pending:  map[rune]bool{},
+	}
+	saved, err := c.store.Load()
+	c.err = err
+	if saved != nil && saved.SpecialChecksum == c.cp.SpecialChecksum && saved.StandardChecksum == c.cp.StandardChecksum &&
+		saved.Start == start && saved.End == end && saved.Next >= start {
+		// Next 之后的字符即使已经完成也会重新比较，对应的结果不能保留
+		c.cp.Next = saved.Next
+		c.cp.Results = slices.DeleteFunc(slices.Clone(saved.Results), func(r MappingResult) bool { return r.Special >= saved.Next })
+	}
+	c.dispatched = c.cp.Next - 1
+	return c
+}
+
+func (c *checkpointer) dispatch(r rune) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.pending[r] = true
+	c.dispatched = r
+	c.mu.Unlock()
+}
+
+func (c *checkpointer) complete(r rune, result MappingResult, ok bool) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pending, r)
+	if ok {
+		c.cp.Results = append(c.cp.Results, result)
+	}
+	if c.completed++; c.interval > 0 && c.completed%c.interval == 0 {
+		c.save()
+	}
+}
+
+// finish 保存最终的进度并返回过程中遇到的第一个读写错误
+func (c *checkpointer) finish() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.save()
+	return c.err
+}
+
+// save 需要在持有 mu 时调用
+func (c *checkpointer) save() {
+	c.cp.Next = c.dispatched + 1
+	for r := range c.pending {
+		c.cp.Next = min(c.cp.Next, r)
+	}
+	cp := c.cp
+	cp.Results = slices.Clone(c.cp.Results)
+	slices.SortFunc(cp.Results, func(a, b MappingResult) int { return int(a.Special - b.Special) })
+	if err := c.store.Save(&cp); err != nil && c.err == nil {
+		c.err = err
+	}
+}
@@ -0,0 +1,122 @@
+package mapper
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// memoryCheckpointStore 把进度保存在内存中，并记录保存的次数
+type memoryCheckpointStore struct {
+	mu    sync.Mutex
+	cp    *Checkpoint
+	saves int
+}
+
+func (s *memoryCheckpointStore) Load() (*Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cp, nil
+}
+
+func (s *memoryCheckpointStore) Save(cp *Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cp = cp
+	s.saves++
+	return nil
+}
+
+func TestGlyphOutlineMapper_WithCheckpoint(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+
+	store := &memoryCheckpointStore{}
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithCheckpoint(store, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := mapper.Mapping(0xE000, 0xE003)
+	if len(got) != 2 || got[0xE000] != 'A' || got[0xE001] != 'B' {
+		t.Fatalf("got %v, want U+E000 => 'A', U+E001 => 'B'", got)
+	}
+	if store.saves != 3 || store.cp.Next != 0xE004 || len(store.cp.Results) != 2 {
+		t.Errorf("got %d saves, checkpoint %+v, want 3 saves up to U+E004", store.saves, store.cp)
+	}
+
+	// 伪造只完成了 U+E000 的进度，它的结果应当被沿用而不是重新比较；Next 之后的结果会被丢弃
+	store.cp = &Checkpoint{
+		SpecialChecksum:  store.cp.SpecialChecksum,
+		StandardChecksum: store.cp.StandardChecksum,
+		Start:            0xE000,
+		End:              0xE003,
+		Next:             0xE001,
+		Results:          []MappingResult{{Special: 0xE000, Standard: 'Z'}, {Special: 0xE001, Standard: 'Z'}},
+	}
+	got = mapper.Mapping(0xE000, 0xE003)
+	if len(got) != 2 || got[0xE000] != 'Z' || got[0xE001] != 'B' {
+		t.Errorf("resumed got %v, want U+E000 => 'Z', U+E001 => 'B'", got)
+	}
+
+	// 范围不同时忽略保存的进度
+	if got := mapper.Mapping(0xE000, 0xE001); got[0xE000] != 'A' {
+		t.Errorf("other range got %v, want U+E000 => 'A'", got)
+	}
+}
+
+func TestGlyphOutlineMapper_CheckpointCancelResume(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+
+	// 取消时可能已经分发了一部分字符，它们的比较没有完成，不能被记录为已完成
+	for range 50 {
+		store := &memoryCheckpointStore{}
+		mapper, err := NewGlyphOutlineMapper(special, standard, WithCheckpoint(store, 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := mapper.MappingContext(ctx, 0xE000, 0xE003); err == nil {
+			t.Fatal("cancelled mapping returned no error")
+		}
+		if store.cp != nil && store.cp.Next != 0xE000 {
+			t.Fatalf("cancelled checkpoint = %+v, want Next = U+E000", store.cp)
+		}
+		if got := mapper.Mapping(0xE000, 0xE003); len(got) != 2 || got[0xE000] != 'A' || got[0xE001] != 'B' {
+			t.Fatalf("resumed got %v, want U+E000 => 'A', U+E001 => 'B'", got)
+		}
+	}
+}
+
+func TestFileCheckpointStore(t *testing.T) {
+	store := FileCheckpointStore(filepath.Join(t.TempDir(), "checkpoint.json"))
+	if cp, err := store.Load(); cp != nil || err != nil {
+		t.Fatalf("Load before Save = %v, %v, want nil, nil", cp, err)
+	}
+	want := &Checkpoint{Start: 0xE000, End: 0xF8FF, Next: 0xE100, Results: []MappingResult{{Special: 0xE000, Standard: 'A', Score: 1}}}
+	if err := store.Save(want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Next != want.Next || len(got.Results) != 1 || !reflect.DeepEqual(got.Results[0], want.Results[0]) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,85 @@
+package mapper
+
+import (
+	"cmp"
+	"encoding/binary"
+	"slices"
+	"unicode"
+)
+
+// cmapRunes 返回 cmap 表中所有 Unicode 子表（格式 4、6 和 12）编码的字符，按码位升序排列且不重复。
+// 无法识别或者越界的子表会被跳过。各子表的区间先合并再展开，重叠或重复的区间不会让结果超过 Unicode 的全部码位
+func cmapRunes(cmap []byte) []rune {
+	if len(cmap) < 4 {
+		return nil
+	}
+	var ranges []RuneRange
+	n := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < n && 4+8*i+8 <= len(cmap); i++ {
+		record := cmap[4+8*i:]
+		platform, encoding := binary.BigEndian.Uint16(record), binary.BigEndian.Uint16(record[2:])
+		if platform != 0 && !(platform == 3 && (encoding == 1 || encoding == 10)) {
+			continue
+		}
+		offset := int(binary.BigEndian.Uint32(record[4:]))
+		if offset+2 > len(cmap) {
+			continue
+		}
+		ranges = appendSubtableRanges(ranges, cmap[offset:])
+	}
+	slices.SortFunc(ranges, func(a, b RuneRange) int { return cmp.Compare(a.Start, b.Start) })
+	var runes []rune
+	next := rune(0) // 还没有展开的最小码位
+	for _, rr := range ranges {
+		for r := max(rr.Start, next); r <= rr.End; r++ {
+			runes = append(runes, r)
+		}
+		next = max(next, rr.End+1)
+	}
+	return runes
+}
+
+// appendSubtableRanges 追加子表中编码的字符区间，区间都在 [0, 0x10FFFF] 之内
+func appendSubtableRanges(ranges []RuneRange, sub []byte) []RuneRange {
+	switch binary.BigEndian.Uint16(sub) {
+	case 4:
+		if len(sub) < 14 {
+			return ranges
+		}
+		segments := int(binary.BigEndian.Uint16(sub[6:])) / 2
+		if 16+8*segments > len(sub) {
+			return ranges
+		}
+		ends, starts := sub[14:], sub[16+2*segments:]
+		for i := 0; i < segments; i++ {
+			// 0xFFFF 是格式 4 末尾的哨兵段
+			start, end := rune(binary.BigEndian.Uint16(starts[2*i:])), min(rune(binary.BigEndian.Uint16(ends[2*i:])), 0xFFFE)
+			if start <= end {
+				ranges = append(ranges, RuneRange{Start: start, End: end})
+			}
+		}
+	case 6:
+		if len(sub) < 10 {
+			return ranges
+		}
+		// 字形索引数组之外的字符没有意义，格式 6 也只能编码基本多文种平面
+		first, count := rune(binary.BigEndian.Uint16(sub[6:])), rune(binary.BigEndian.Uint16(sub[8:]))
+		count = min(count, rune(len(sub)-10)/2, 0x10000-first)
+		if count > 0 {
+			ranges = append(ranges, RuneRange{Start: first, End: first + count - 1})
+		}
+	case 12:
+		if len(sub) < 16 {
+			return ranges
+		}
+		groups := int(binary.BigEndian.Uint32(sub[12:]))
+		for i := 0; i < groups && 16+12*i+12 <= len(sub); i++ {
+			group := sub[16+12*i:]
+			start, end := binary.BigEndian.Uint32(group), min(binary.BigEndian.Uint32(group[4:]), unicode.MaxRune)
+			if start <= end {
+				ranges = append(ranges, RuneRange{Start: rune(start), End: rune(end)})
+			}
+		}
+	}
+	return ranges
+}
@@ -0,0 +1,88 @@
+package mapper
+
+import (
+	"encoding/binary"
+	"slices"
+	"testing"
+)
+
+func TestCmapRunes(t *testing.T) {
+	// 格式 4：两个分段 [0x41, 0x43] 和结束标记 0xFFFF
+	format4 := []byte{0, 4, 0, 0, 0, 0, 0, 4, 0, 0, 0, 0, 0, 0}
+	for _, v := range []uint16{0x43, 0xFFFF, 0, 0x41, 0xFFFF} {
+		format4 = binary.BigEndian.AppendUint16(format4, v)
+	}
+	format4 = append(format4, make([]byte, 8)...)
+	format12 := encodeTestCmap(map[rune]rune{0x42: 1, 0xE000: 1, 0x20000: 2})[12:]
+
+	var cmap []byte
+	cmap = binary.BigEndian.AppendUint16(cmap, 0)
+	cmap = binary.BigEndian.AppendUint16(cmap, 3)
+	for _, record := range [][3]int{{3, 1, 28}, {3, 10, 28 + len(format4)}, {1, 0, 28}} {
+		cmap = binary.BigEndian.AppendUint16(cmap, uint16(record[0]))
+		cmap = binary.BigEndian.AppendUint16(cmap, uint16(record[1]))
+		cmap = binary.BigEndian.AppendUint32(cmap, uint32(record[2]))
+	}
+	cmap = append(append(cmap, format4...), format12...)
+
+	if got, want := cmapRunes(cmap), []rune{0x41, 0x42, 0x43, 0xE000, 0x20000}; !slices.Equal(got, want) {
+		t.Errorf("got %U, want %U", got, want)
+	}
+}
+
+func TestGlyphOutlineMapper_MappingAll(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{'x': 1, 0xE123: 2, 0xF8F0: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := mapper.SpecialRunes(), []rune{'x', 0xE123, 0xF8F0}; !slices.Equal(got, want) {
+		t.Errorf("SpecialRunes = %U, want %U", got, want)
+	}
+	got := mapper.MappingAll()
+	if len(got) != 3 || got['x'] != 'B' || got[0xE123] != 'A' || got[0xF8F0] != 'B' {
+		t.Errorf("MappingAll = %v", got)
+	}
+}
+
+func TestCmapRunes_OverlappingRanges(t *testing.T) {
+	// 格式 12：一千个覆盖全部码位的重复分组，结束码位超出 Unicode 的范围
+	const groups = 1000
+	format12 := []byte{0, 12, 0, 0}
+	format12 = binary.BigEndian.AppendUint32(format12, uint32(16+12*groups))
+	format12 = binary.BigEndian.AppendUint32(format12, 0)
+	format12 = binary.BigEndian.AppendUint32(format12, groups)
+	for range groups {
+		format12 = binary.BigEndian.AppendUint32(format12, 0)
+		format12 = binary.BigEndian.AppendUint32(format12, 0xFFFFFFFF)
+		format12 = binary.BigEndian.AppendUint32(format12, 1)
+	}
+	// 格式 6：声明了 0x8000 个字符，但字形索引数组只有 2 项
+	format6 := []byte{0, 6, 0, 14, 0, 0, 0xFF, 0xFE, 0x80, 0x00, 0, 1, 0, 1}
+
+	cmap := []byte{0, 0, 0, 2}
+	for i, offset := range []int{20, 20 + len(format12)} {
+		cmap = binary.BigEndian.AppendUint16(cmap, 3)
+		cmap = binary.BigEndian.AppendUint16(cmap, []uint16{10, 1}[i])
+		cmap = binary.BigEndian.AppendUint32(cmap, uint32(offset))
+	}
+	cmap = append(append(cmap, format12...), format6...)
+
+	got := cmapRunes(cmap)
+	if len(got) != 0x110000 || got[0] != 0 || got[len(got)-1] != 0x10FFFF {
+		t.Errorf("got %d runes, want every code point once", len(got))
+	}
+
+	format6Only := append([]byte{0, 0, 0, 1, 0, 3, 0, 1, 0, 0, 0, 12}, format6...)
+	if got, want := cmapRunes(format6Only), []rune{0xFFFE, 0xFFFF}; !slices.Equal(got, want) {
+		t.Errorf("format 6: got %U, want %U", got, want)
+	}
+}
@@ -0,0 +1,136 @@
+package mapper
+
+import (
+	"iter"
+	"maps"
+	"slices"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// RuneRange 表示闭区间 [Start, End] 内的字符
+type RuneRange struct {
+	Start rune
+	End   rune
+}
+
+// MapperConfig 是 GlyphOutlineMapper 当前生效配置的快照
+type MapperConfig struct {
+	Concurrency          int              // 并发比较的字符数
+	Tolerance            fixed.Int26_6    // 逐点比较坐标时允许的误差
+	Hinting              font.Hinting     // 加载字形时使用的 hinting 方式
+	CompareScale         fixed.Int26_6    // 加载字形时 1 em 对应的 26.6 定点数
+	CandidateRanges      []RuneRange      // 在标准字体中查找候选字符的范围
+	ShapeSignature       bool             // 是否使用轮廓形状签名比较
+	SignatureThreshold   float64          // 形状签名的相似度阈值
+	FloatCoordinates     bool             // 是否使用原始浮点坐标比较
+	FloatTolerance       float64          // 浮点坐标比较的误差，以 em 为单位
+	MatchStrategy        MatchStrategy    // 存在多个匹配候选时的选择策略
+	MatchThreshold       float64          // 按得分判断匹配时的最低得分，0 表示逐点判断
+	IgnorePointFlags     bool             // 逐点比较时是否忽略曲线标志
+	MajorContours        int              // 只比较面积最大的若干个轮廓，0 表示全部比较
+	TranslationInvariant bool             // 比较之前是否把字形平移到边界框原点
+	ScaleInvariant       bool             // 比较之前是否把字形等比缩放到 1 em 的边界框
+	DirectionInvariant   bool             // 比较之前是否把轮廓统一为逆时针方向
+	StartPointInvariant  bool             // 逐点比较时是否允许轮廓的起点不同
+	ContourPermutation   bool             // 逐点比较时是否允许轮廓以不同的顺序对应
+	ContourResampling    int              // 比较之前把每个轮廓重新采样的点数，0 表示不采样
+	CurveFlattening      bool             // 比较之前是否按 Flatness 展开曲线
+	Transforms           []GlyphTransform // 特殊字形本身没有匹配时依次尝试的变换
+	PerceptualHash       bool             // 是否按感知哈希比较
+	HashDistance         int              // 感知哈希允许的最大汉明距离
+	HashLSHTables        int              // 感知哈希近似索引的表数，0 表示不建立
+	HashLSHBits          int              // 感知哈希近似索引每个表采样的位数
+	RawGlyphMatch        bool             // 是否先按 glyf 原始数据的哈希查找完全相同的字形
+	OutlineHash          bool             // 是否先按归一化轮廓的哈希查找一致的字形
+	AdvanceFilter        float64          // 前进宽度和左侧间距允许的最大差异，0 表示不过滤
+	NearestCandidates    int              // 每个字符只比较特征最接近的候选数量，0 表示全部比较
+	Alternatives         int              // 每个匹配结果附带的其他候选数量
+	OCRFallback          bool             // 没有匹配时是否交给 WithOCRFallback 设置的 recognizer 识别
+	Flatness             float64          // 展开曲线的精度，单位是 1000 单位 em 下的字体单位
+	IgnoreRunes          []rune           // 映射时跳过的字符，按码位升序排列
+	IgnoreRanges         []RuneRange      // 映射时跳过的字符范围
+}
+
+// Config 返回当前生效的配置，返回值是副本，修改它不会影响 mapper
+func (g *GlyphOutlineMapper) Config() MapperConfig {
+	return MapperConfig{
+		Concurrency:          g.concurrent,
+		Tolerance:            g.tolerance,
+		Hinting:              g.hinting,
+		CompareScale:         g.scale,
+		CandidateRanges:      slices.Clone(g.candidateRanges),
+		ShapeSignature:       g.shapeSignature,
+		SignatureThreshold:   g.signatureThreshold,
+		FloatCoordinates:     g.floatCoordinates,
+		FloatTolerance:       g.floatTolerance,
+		MatchStrategy:        g.strategy,
+		MatchThreshold:       g.matchThreshold,
+		IgnorePointFlags:     g.ignorePointFlags,
+		MajorContours:        g.majorContours,
+		TranslationInvariant: g.translationInvariant,
+		ScaleInvariant:       g.scaleInvariant,
+		DirectionInvariant:   g.directionInvariant,
+		StartPointInvariant:  g.startPointInvariant,
+		ContourPermutation:   g.contourPermutation,
+		ContourResampling:    g.resampleCount,
+		CurveFlattening:      g.curveFlattening,
+		Transforms:           slices.Clone(g.transforms),
+		PerceptualHash:       g.perceptualHash,
+		HashDistance:         g.hashDistance,
+		HashLSHTables:        g.lshTables,
+		HashLSHBits:          g.lshBits,
+		RawGlyphMatch:        g.rawGlyphMatch,
+		OutlineHash:          g.outlineHashing,
+		AdvanceFilter:        g.advanceFilter,
+		NearestCandidates:    g.nearestK,
+		Alternatives:         g.alternatives,
+		OCRFallback:          g.recognizer != nil,
+		Flatness:             g.flatness,
+		IgnoreRunes:          slices.Sorted(maps.Keys(g.ignoreRunes)),
+		IgnoreRanges:         slices.Clone(g.ignoreRanges),
+	}
+}
+
+// SetIgnoreRunes 设置映射时跳过的字符，例如空格、零宽字符和装饰性字形。这些字符既不会作为特殊字符被映射，
+// 也不会作为标准字体中的候选被匹配，避免浪费时间和产生无意义的匹配。传入 nil 清除设置
+func (g *GlyphOutlineMapper) SetIgnoreRunes(runes []rune) {
+	g.ignoreRunes = make(map[rune]bool, len(runes))
+	for _, r := range runes {
+		g.ignoreRunes[r] = true
+	}
+	g.resetCache()
+}
+
+// SetIgnoreRanges 与 SetIgnoreRunes 相同，但按范围设置，两者同时生效
+func (g *GlyphOutlineMapper) SetIgnoreRanges(ranges []RuneRange) {
+	g.ignoreRanges = slices.Clone(ranges)
+	g.resetCache()
+}
+
+// ignored 判断字符是否被设置为跳过
+func (g *GlyphOutlineMapper) ignored(r rune) bool {
+	if g.ignoreRunes[r] {
+		return true
+	}
+	for _, rr := range g.ignoreRanges {
+		if r >= rr.Start && r <= rr.End {
+			return true
+		}
+	}
+	return false
+}
+
+// candidateRunes 按顺序遍历候选范围内的所有字符
+func (g *GlyphOutlineMapper) candidateRunes() iter.Seq[rune] {
+	return func(yield func(rune) bool) {
+		for _, rr := range g.candidateRanges {
+			for r := rr.Start; r <= rr.End; r++ {
+				if !yield(r) {
+					return
+				}
+			}
+		}
+	}
+}
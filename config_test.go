@@ -0,0 +1,316 @@
+package mapper
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestGlyphOutlineMapper_Config(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{square(0, 0, 500)}, advance: 500}}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{square(0, 0, 500)}, advance: 500}}, map[rune]rune{'A': 1})
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithShapeSignatureMatching(0.9))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapper.SetConcurrent(4)
+
+	config := mapper.Config()
+	if config.Concurrency != 4 || config.Tolerance != 10 || config.CompareScale != fixed.I(1000) {
+		t.Errorf("unexpected config: %+v", config)
+	}
+	if !config.ShapeSignature || config.SignatureThreshold != 0.9 {
+		t.Errorf("shape signature mode not reported: %+v", config)
+	}
+	if len(config.CandidateRanges) != 1 || config.CandidateRanges[0] != (RuneRange{Start: 0, End: 'A'}) {
+		t.Fatalf("unexpected candidate ranges: %v", config.CandidateRanges)
+	}
+
+	config.CandidateRanges[0].End = 0
+	if mapper.Config().CandidateRanges[0].End != 'A' {
+		t.Error("mutating the snapshot changed the mapper")
+	}
+}
+
+func TestNewGlyphOutlineMapper_Options(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{square(0, 0, 500)}, advance: 500}}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{square(0, 0, 501)}, advance: 500}}, map[rune]rune{'A': 1})
+
+	strict, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strict.GlyphOutlineEqual(0xE000, 'A') {
+		t.Error("a one-unit difference should exceed the default tolerance")
+	}
+
+	// 1000 ppem 下 1 个字体单位是 64，放宽到 64 后应当匹配
+	loose, err := NewGlyphOutlineMapper(special, standard,
+		WithConcurrency(2), WithTolerance(64), WithHinting(font.HintingFull), WithScale(fixed.I(1000)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loose.GlyphOutlineEqual(0xE000, 'A') {
+		t.Error("a one-unit difference should be within tolerance 64")
+	}
+	config := loose.Config()
+	if config.Concurrency != 2 || config.Tolerance != 64 || config.Hinting != font.HintingFull {
+		t.Errorf("options not applied: %+v", config)
+	}
+
+	// 在更小的尺寸下同样的差异被取整掉
+	small, err := NewGlyphOutlineMapper(special, standard, WithScale(fixed.I(10)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !small.GlyphOutlineEqual(0xE000, 'A') {
+		t.Error("a one-unit difference should vanish at 10 ppem")
+	}
+}
+
+func TestWithProgress(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{square(0, 0, 500)}, advance: 500}}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{square(0, 0, 500)}, advance: 500}}, map[rune]rune{'A': 1})
+
+	var calls, last int
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithProgress(func(done, total int) {
+		calls++
+		if done != last+1 || total != 16 {
+			t.Errorf("progress(%d, %d) after %d, want (%d, 16)", done, total, last, last+1)
+		}
+		last = done
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapper.Mapping(0xE000, 0xE00F)
+	if calls != 16 {
+		t.Errorf("progress called %d times, want 16", calls)
+	}
+}
+
+func TestWithOnMatch(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{square(0, 0, 500)}, advance: 500}}, map[rune]rune{0xE000: 1, 0xE001: 1})
+	standard := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{square(0, 0, 500)}, advance: 500}}, map[rune]rune{'A': 1})
+
+	matches := map[rune]rune{}
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithOnMatch(func(special, standard rune, score float64) {
+		if score != 1 {
+			t.Errorf("score for %U = %v, want 1", special, score)
+		}
+		matches[special] = standard
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapper.Mapping(0xE000, 0xE00F)
+	if len(matches) != 2 || matches[0xE000] != 'A' || matches[0xE001] != 'A' {
+		t.Errorf("got matches %v, want U+E000 and U+E001 => 'A'", matches)
+	}
+}
+
+func TestWithDeterministicOrder(t *testing.T) {
+	cmap := map[rune]rune{}
+	for r := rune(0xE000); r < 0xE040; r++ {
+		cmap[r] = 1
+	}
+	special := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{square(0, 0, 500)}, advance: 500}}, cmap)
+	standard := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{square(0, 0, 500)}, advance: 500}}, map[rune]rune{'A': 1})
+
+	var order []rune
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithConcurrency(8), WithDeterministicOrder(), WithOnMatch(func(special, _ rune, _ float64) {
+		order = append(order, special)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapper.Mapping(0xE000, 0xE0FF)
+	if len(order) != len(cmap) || !slices.IsSorted(order) {
+		t.Errorf("OnMatch called in order %U, want %d runes in ascending order", order, len(cmap))
+	}
+
+	var streamed []rune
+	for result := range mapper.MappingStream(0xE000, 0xE0FF) {
+		streamed = append(streamed, result.Special)
+	}
+	if !slices.Equal(streamed, order[:len(cmap)]) {
+		t.Errorf("MappingStream order %U differs from OnMatch order", streamed)
+	}
+}
+
+func TestGlyphOutlineMapper_SetCandidateRanges(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{square(0, 0, 500)}, advance: 500}}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(0, 0, 500)}, advance: 500},
+	}, map[rune]rune{'0': 1, 'O': 1})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, standardRune, ok := mapper.MappingRune(0xE000); !ok || standardRune != '0' {
+		t.Fatalf("got %q (ok=%v), want '0'", standardRune, ok)
+	}
+
+	mapper.SetCandidateRanges([]RuneRange{{Start: 'A', End: 'Z'}})
+	if _, standardRune, ok := mapper.MappingRune(0xE000); !ok || standardRune != 'O' {
+		t.Fatalf("got %q (ok=%v), want 'O'", standardRune, ok)
+	}
+	mapper.SetCandidateRanges([]RuneRange{{Start: 'a', End: 'z'}})
+	if _, _, ok := mapper.MappingRune(0xE000); ok {
+		t.Error("no candidate in range should match")
+	}
+}
+
+func TestGlyphOutlineMapper_SetTolerance(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{square(0, 0, 500)}, advance: 500}}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{square(0, 0, 501)}, advance: 500}}, map[rune]rune{'A': 1})
+
+	for _, opts := range [][]Option{nil, {WithScale(fixed.I(2000))}, {WithFloatCoordinates(0)}} {
+		mapper, err := NewGlyphOutlineMapper(special, standard, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 1 个字体单位是 em 的 0.1%
+		mapper.SetTolerance(0.05)
+		if mapper.GlyphOutlineEqual(0xE000, 'A') {
+			t.Errorf("%+v: 0.1%% difference should exceed 0.05%% tolerance", mapper.Config())
+		}
+		mapper.SetTolerance(0.2)
+		if !mapper.GlyphOutlineEqual(0xE000, 'A') {
+			t.Errorf("%+v: 0.1%% difference should be within 0.2%% tolerance", mapper.Config())
+		}
+	}
+}
+
+func TestGlyphOutlineMapper_SetHinting(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{square(0, 0, 500)}, advance: 500}}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{square(0, 0, 500)}, advance: 500}}, map[rune]rune{'A': 1})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mapper.Warm(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, hinting := range []font.Hinting{font.HintingVertical, font.HintingFull} {
+		mapper.SetHinting(hinting)
+		if mapper.cache != nil {
+			t.Errorf("%v: changing hinting should drop the glyph cache", hinting)
+		}
+		if got := mapper.Config().Hinting; got != hinting {
+			t.Errorf("Config().Hinting = %v, want %v", got, hinting)
+		}
+		if _, standardRune, ok := mapper.MappingRune(0xE000); !ok || standardRune != 'A' {
+			t.Errorf("%v: got %q (ok=%v), want 'A'", hinting, standardRune, ok)
+		}
+	}
+}
+
+func TestGlyphOutlineMapper_SetCompareScale(t *testing.T) {
+	// 两个字体的 unitsPerEm 不同，但正方形都是半个 em
+	special := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{square(250, 0, 500)}, advance: 1000}}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(2048, []testGlyph{{contours: [][]testPoint{square(512, 0, 1024)}, advance: 2048}}, map[rune]rune{'A': 1})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ppem := range []int{1000, 2048, 16} {
+		mapper.SetCompareScale(ppem)
+		if got := mapper.Config().CompareScale; got != fixed.I(ppem) {
+			t.Errorf("CompareScale = %v, want %v", got, fixed.I(ppem))
+		}
+		if _, standardRune, ok := mapper.MappingRune(0xE000); !ok || standardRune != 'A' {
+			t.Errorf("ppem %d: got %q (ok=%v), want 'A'", ppem, standardRune, ok)
+		}
+	}
+}
+
+func TestGlyphOutlineMapper_SetToleranceFollowsScale(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{square(250, 0, 500)}, advance: 1000}}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(2048, []testGlyph{{contours: [][]testPoint{square(512, 0, 1024)}, advance: 2048}}, map[rune]rune{'A': 1})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// em 的 0.1% 在 1000 ppem 下是 64 个 26.6 单位，调整尺寸之后按新的尺寸换算
+	mapper.SetTolerance(0.1)
+	mapper.SetCompareScale(4000)
+	if got := mapper.Config().Tolerance; got != 256 {
+		t.Errorf("Tolerance after SetCompareScale(4000) = %d, want 256", got)
+	}
+	// WithTolerance 设置的绝对容差不随尺寸变化
+	absolute, err := NewGlyphOutlineMapper(special, standard, WithTolerance(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	absolute.SetCompareScale(4000)
+	if got := absolute.Config().Tolerance; got != 20 {
+		t.Errorf("WithTolerance after SetCompareScale(4000) = %d, want 20", got)
+	}
+}
+
+func TestGlyphOutlineMapper_SetIgnoreRunes(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(0, 0, 500)}, advance: 500},
+		{advance: 500},
+	}, map[rune]rune{0xE000: 1, 0xE001: 1, 0xE002: 2})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(0, 0, 500)}, advance: 500},
+		{contours: [][]testPoint{square(0, 0, 500)}, advance: 500},
+	}, map[rune]rune{'A': 1, 'B': 2})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mapper.SetIgnoreRunes([]rune{'A'})
+	mapper.SetIgnoreRanges([]RuneRange{{Start: 0xE001, End: 0xE001}})
+	got := mapper.Mapping(0xE000, 0xE002)
+	if len(got) != 1 || got[0xE000] != 'B' {
+		t.Errorf("got %v, want only U+E000 => 'B'", got)
+	}
+	config := mapper.Config()
+	if len(config.IgnoreRunes) != 1 || config.IgnoreRunes[0] != 'A' || len(config.IgnoreRanges) != 1 {
+		t.Errorf("ignore settings not reported: %+v", config)
+	}
+}
+
+func TestWithDeadline(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{square(0, 0, 500)}, advance: 500}}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{square(0, 0, 500)}, advance: 500}}, map[rune]rune{'A': 1})
+
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithDeadline(time.Nanosecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mapper.Warm(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got, errs := mapper.MappingWithErrors(0xE000, 0xE000)
+	if len(got) != 0 || len(errs) != 0 {
+		t.Errorf("got %v, errors %v, want no result and no load errors", got, errs)
+	}
+	var runeErr *RuneError
+	if unmapped := mapper.Unmapped(); len(unmapped) != 1 || unmapped[0].Reason != UnmappedTimeout ||
+		!errors.As(unmapped[0].Err, &runeErr) || runeErr.Rune != 0xE000 || !errors.Is(unmapped[0].Err, ErrCompareTimeout) {
+		t.Errorf("Unmapped() = %v, want a timeout for U+E000", unmapped)
+	}
+	if _, err := mapper.MappingRuneE(0xE000); !errors.Is(err, ErrCompareTimeout) || errors.Is(err, ErrGlyphLoad) {
+		t.Errorf("MappingRuneE error = %v, want ErrCompareTimeout", err)
+	}
+
+	relaxed, err := NewGlyphOutlineMapper(special, standard, WithDeadline(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := relaxed.Mapping(0xE000, 0xE000); got[0xE000] != 'A' {
+		t.Errorf("got %v, want U+E000 => 'A'", got)
+	}
+}
@@ -0,0 +1,224 @@
+package mapper
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/math/fixed"
+)
+
+// contourRange 是 outline.points 中一个轮廓所占的区间 [start, end)
+type contourRange struct {
+	start, end int
+	bounds     [4]int32 // minX, minY, maxX, maxY
+}
+
+// glyphContours 返回字形中每个轮廓的区间和边界
+func glyphContours(o *outline) []contourRange {
+	contours := make([]contourRange, 0, len(o.ends))
+	start := 0
+	for _, end := range o.ends {
+		c := contourRange{start: start, end: end}
+		for i, p := range o.points[start:end] {
+			x, y := int32(p.X), int32(p.Y)
+			if i == 0 {
+				c.bounds = [4]int32{x, y, x, y}
+				continue
+			}
+			c.bounds = [4]int32{min(c.bounds[0], x), min(c.bounds[1], y), max(c.bounds[2], x), max(c.bounds[3], y)}
+		}
+		contours = append(contours, c)
+		start = end
+	}
+	return contours
+}
+
+// canonicalizeContours 把字形的轮廓按固定顺序重新排列：依次按边界的 minX、minY、maxX、maxY
+// 和点数排序，轮廓内部的点保持字形表中的顺序。
+//
+// truetype 按组件在复合字形中出现的顺序展开轮廓，引用相同子字形但组件顺序不同的复合字形，
+// 或者与之等价的简单字形，展开后的点序各不相同。排序后它们的点序一致，可以逐点比较
+func canonicalizeContours(o *outline) {
+	contours := glyphContours(o)
+	if slices.IsSortedFunc(contours, compareContours) {
+		return
+	}
+	slices.SortStableFunc(contours, compareContours)
+
+	points := make([]truetype.Point, 0, len(o.points))
+	for i, c := range contours {
+		points = append(points, o.points[c.start:c.end]...)
+		o.ends[i] = len(points)
+	}
+	o.points = points
+}
+
+func compareContours(a, b contourRange) int {
+	for i := range a.bounds {
+		if c := cmp.Compare(a.bounds[i], b.bounds[i]); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(a.end-a.start, b.end-b.start)
+}
+
+func (c contourRange) area() int64 {
+	return int64(c.bounds[2]-c.bounds[0]) * int64(c.bounds[3]-c.bounds[1])
+}
+
+// keepMajorContours 只保留边界框面积最大的 n 个轮廓，保留下来的轮廓维持原有顺序
+func keepMajorContours(o *outline, n int) {
+	if n <= 0 || len(o.ends) <= n {
+		return
+	}
+	contours := glyphContours(o)
+	order := make([]int, len(contours))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(contours[b].area(), contours[a].area())
+	})
+	keep := order[:n]
+	slices.Sort(keep)
+
+	points := make([]truetype.Point, 0, len(o.points))
+	ends := o.ends[:0]
+	for _, i := range keep {
+		points = append(points, o.points[contours[i].start:contours[i].end]...)
+		ends = append(ends, len(points))
+	}
+	o.points, o.ends = points, ends
+}
+
+// compareCyclicOutlines 与 compareGlyphOutlines 相同，但每个轮廓可以循环移位后再逐点比较，
+// 每个轮廓取偏差最小的移位
+func compareCyclicOutlines(outline1, outline2 *outline, tolerance fixed.Int26_6, ignoreFlags bool) (bool, float64) {
+	if !slices.Equal(outline1.ends, outline2.ends) || len(outline1.points) != len(outline2.points) {
+		return false, math.Inf(1)
+	}
+	var total int64
+	start := 0
+	for _, end := range outline1.ends {
+		best, ok := contourDeviation(outline1.points[start:end], outline2.points[start:end], tolerance, ignoreFlags, true)
+		start = end
+		if !ok {
+			return false, math.Inf(1)
+		}
+		total += best
+	}
+	return true, relativeDeviation(float64(total), float64(len(outline1.points)), float64(tolerance))
+}
+
+// contourDeviation 逐点比较两个轮廓，返回偏差之和以及是否每个点都在容差之内。
+// cyclic 为 true 时尝试 b 的全部循环移位，取偏差最小的一个
+func contourDeviation(a, b []truetype.Point, tolerance fixed.Int26_6, ignoreFlags, cyclic bool) (int64, bool) {
+	if len(a) != len(b) {
+		return 0, false
+	}
+	if !cyclic {
+		return cyclicDeviation(a, b, 0, tolerance, ignoreFlags)
+	}
+	best, found := int64(0), false
+	for shift := range b {
+		sum, ok := cyclicDeviation(a, b, shift, tolerance, ignoreFlags)
+		if ok && (!found || sum < best) {
+			best, found = sum, true
+			if sum == 0 {
+				break
+			}
+		}
+	}
+	return best, found
+}
+
+// comparePermutedOutlines 与 compareGlyphOutlines 相同，但轮廓可以按任意顺序对应：依次为 outline1 的每个轮廓
+// 在 outline2 尚未对应的轮廓中贪心地选出边界框在容差之内、逐点偏差最小的一个。
+// cyclic 为 true 时同时允许每个轮廓的起点不同，见 compareCyclicOutlines
+func comparePermutedOutlines(outline1, outline2 *outline, tolerance fixed.Int26_6, ignoreFlags, cyclic bool) (bool, float64) {
+	if len(outline1.ends) != len(outline2.ends) || len(outline1.points) != len(outline2.points) {
+		return false, math.Inf(1)
+	}
+	contours1, contours2 := glyphContours(outline1), glyphContours(outline2)
+	return pairContours(outline1, outline2, contours1, contours2, [][2]int{{0, len(contours1)}}, tolerance, ignoreFlags, cyclic)
+}
+
+// compareTiedOutlines 与 comparePermutedOutlines 相同，但只有边界的每条边都相差不超过 tieTolerance 的相邻轮廓之间
+// 可以交换顺序。加载时轮廓按精确的边界排序，这样的轮廓在两个字体中可能因为容差以内的误差排成不同的顺序；
+// 边界明显不同的轮廓排序结果可靠，仍然按位置对应。两个字形都没有这样的轮廓时直接返回 false
+func compareTiedOutlines(outline1, outline2 *outline, tolerance, tieTolerance fixed.Int26_6, ignoreFlags, cyclic bool) (bool, float64) {
+	if len(outline1.ends) != len(outline2.ends) || len(outline1.points) != len(outline2.points) {
+		return false, math.Inf(1)
+	}
+	contours1, contours2 := glyphContours(outline1), glyphContours(outline2)
+	var groups [][2]int
+	tied := false
+	start := 0
+	for i := 1; i <= len(contours1); i++ {
+		if i < len(contours1) && (boundsWithin(contours1[i-1].bounds, contours1[i].bounds, tieTolerance) ||
+			boundsWithin(contours2[i-1].bounds, contours2[i].bounds, tieTolerance)) {
+			tied = true
+			continue
+		}
+		groups = append(groups, [2]int{start, i})
+		start = i
+	}
+	if !tied {
+		return false, math.Inf(1)
+	}
+	return pairContours(outline1, outline2, contours1, contours2, groups, tolerance, ignoreFlags, cyclic)
+}
+
+// pairContours 在每个下标区间 groups 之内，依次为 outline1 的每个轮廓在 outline2 同一区间中尚未对应的轮廓里
+// 贪心地选出边界框在容差之内、逐点偏差最小的一个
+func pairContours(outline1, outline2 *outline, contours1, contours2 []contourRange, groups [][2]int, tolerance fixed.Int26_6, ignoreFlags, cyclic bool) (bool, float64) {
+	used := make([]bool, len(contours2))
+	var total int64
+	for _, group := range groups {
+		for _, c1 := range contours1[group[0]:group[1]] {
+			a := outline1.points[c1.start:c1.end]
+			best, pick := int64(0), -1
+			for j := group[0]; j < group[1]; j++ {
+				c2 := contours2[j]
+				if used[j] || !boundsWithin(c1.bounds, c2.bounds, tolerance) {
+					continue
+				}
+				if sum, ok := contourDeviation(a, outline2.points[c2.start:c2.end], tolerance, ignoreFlags, cyclic); ok && (pick < 0 || sum < best) {
+					best, pick = sum, j
+				}
+			}
+			if pick < 0 {
+				return false, math.Inf(1)
+			}
+			used[pick] = true
+			total += best
+		}
+	}
+	return true, relativeDeviation(float64(total), float64(len(outline1.points)), float64(tolerance))
+}
+
+// boundsWithin 判断两个轮廓边界的每条边是否都在容差之内
+func boundsWithin(a, b [4]int32, tolerance fixed.Int26_6) bool {
+	for i := range a {
+		if abs(fixed.Int26_6(a[i]-b[i])) > tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// cyclicDeviation 逐点比较 a 与循环移位 shift 之后的 b，返回偏差之和以及是否每个点都在容差之内
+func cyclicDeviation(a, b []truetype.Point, shift int, tolerance fixed.Int26_6, ignoreFlags bool) (int64, bool) {
+	var sum int64
+	for i, p := range a {
+		q := b[(i+shift)%len(b)]
+		dx, dy := abs(p.X-q.X), abs(p.Y-q.Y)
+		if dx > tolerance || dy > tolerance || !ignoreFlags && p.Flags&1 != q.Flags&1 {
+			return 0, false
+		}
+		sum += int64(max(dx, dy))
+	}
+	return sum, true
+}
@@ -0,0 +1,254 @@
+package mapper
+
+import "testing"
+
+func TestGlyphOutlineMapper_CompositeMatchesSimple(t *testing.T) {
+	// 复合字形按“三角形、正方形”的顺序引用两个子字形，标准字体中是等价的简单字形
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 200)}, advance: 1000},
+		{contours: [][]testPoint{triangle(0, 0, 300)}, advance: 1000},
+		{components: []testComponent{{glyph: 2, dx: 500, dy: 100}, {glyph: 1}}, advance: 1000},
+	}, map[rune]rune{0xE000: 3})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(500, 100, 300), square(100, 100, 200)}, advance: 1000},
+		{contours: [][]testPoint{square(100, 100, 200), triangle(500, 100, 300)}, advance: 1000},
+	}, map[rune]rune{'A': 1, 'B': 2})
+
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []rune{'A', 'B'} {
+		if !mapper.GlyphOutlineEqual(0xE000, r) {
+			t.Errorf("composite glyph does not match simple glyph %q", r)
+		}
+	}
+}
+
+func TestGlyphOutlineMapper_CompositeTransforms(t *testing.T) {
+	// U+E000 引用放大 1.5 倍的正方形；U+E001 是嵌套的复合字形，引用 U+E000 的字形并平移，再加上一个三角形
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 200)}, advance: 1000},
+		{contours: [][]testPoint{triangle(0, 0, 300)}, advance: 1000},
+		{components: []testComponent{{glyph: 1, scale: 1.5}}, advance: 1000},
+		{components: []testComponent{{glyph: 3, dx: 400}, {glyph: 2}}, advance: 1000},
+	}, map[rune]rune{0xE000: 3, 0xE001: 4})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(150, 150, 300)}, advance: 1000},
+		{contours: [][]testPoint{square(550, 150, 300), triangle(0, 0, 300)}, advance: 1000},
+	}, map[rune]rune{'A': 1, 'B': 2})
+
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := mapper.Mapping(0xE000, 0xE001)
+	if len(got) != 2 || got[0xE000] != 'A' || got[0xE001] != 'B' {
+		t.Errorf("got %v, want U+E000 => A, U+E001 => B", got)
+	}
+
+	// 展开后的复合字形与等价的简单字形指纹相同
+	for r, standardRune := range map[rune]rune{0xE000: 'A', 0xE001: 'B'} {
+		a, err := Fingerprint(special, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := Fingerprint(standard, standardRune)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Hash != b.Hash || a.Bounds != b.Bounds {
+			t.Errorf("%U: fingerprint %+v, want %+v", r, a, b)
+		}
+	}
+}
+
+func TestGlyphOutlineMapper_SetCompareMajorContours(t *testing.T) {
+	// 特殊字形多了一个小的装饰轮廓
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500), square(700, 700, 50)}, advance: 1000},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 100, 500)}, advance: 1000},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 1000},
+	}, map[rune]rune{'A': 1, 'B': 2})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := mapper.MappingRune(0xE000); ok {
+		t.Fatal("decorated glyph should not match when all contours are compared")
+	}
+
+	mapper.SetCompareMajorContours(1)
+	if _, standardRune, ok := mapper.MappingRune(0xE000); !ok || standardRune != 'B' {
+		t.Fatalf("got %q (ok=%v), want 'B'", standardRune, ok)
+	}
+}
+
+func TestWithStartPointInvariance(t *testing.T) {
+	// 特殊字形的轮廓从第三个点开始，点的循环顺序与标准字形相同
+	rotated := square(100, 100, 500)
+	rotated = append(rotated[2:], rotated[:2]...)
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{rotated, triangle(200, 200, 100)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500), triangle(200, 200, 100)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500), triangle(200, 200, 150)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+
+	plain, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.GlyphOutlineEqual(0xE000, 'A') {
+		t.Error("rotated contour matched without start point invariance")
+	}
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithStartPointInvariance())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, r, ok := mapper.MappingRune(0xE000); !ok || r != 'A' {
+		t.Errorf("got %q (ok=%v), want 'A'", r, ok)
+	}
+	if mapper.GlyphOutlineEqual(0xE000, 'B') {
+		t.Error("a different inner contour should still be rejected")
+	}
+
+	floating, err := NewGlyphOutlineMapper(special, standard, WithStartPointInvariance(), WithFloatCoordinates(0.001))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, r, ok := floating.MappingRune(0xE000); !ok || r != 'A' {
+		t.Errorf("float coordinates: got %q (ok=%v), want 'A'", r, ok)
+	}
+	floating.SetMatchThreshold(0.5)
+	if _, r, ok := floating.MappingRune(0xE000); !ok || r != 'A' {
+		t.Errorf("float coordinates with threshold: got %q (ok=%v), want 'A'", r, ok)
+	}
+}
+
+func TestWithContourPermutation(t *testing.T) {
+	// 16384 unitsPerEm 下 1 个单位在默认容差之内，但足以让按边界框排序后的轮廓顺序相反
+	special := buildTestFont(16384, []testGlyph{
+		{contours: [][]testPoint{square(1001, 1000, 3000), triangle(1000, 6000, 3000)}, advance: 10000},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(16384, []testGlyph{
+		{contours: [][]testPoint{square(1000, 1000, 3000), triangle(1001, 6000, 3000)}, advance: 10000},
+		{contours: [][]testPoint{square(1000, 1000, 3000), triangle(1001, 6000, 2000)}, advance: 10000},
+	}, map[rune]rune{'A': 1, 'B': 2})
+
+	plain, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.GlyphOutlineEqual(0xE000, 'A') {
+		t.Error("reordered contours matched without contour permutation")
+	}
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithContourPermutation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, r, ok := mapper.MappingRune(0xE000); !ok || r != 'A' {
+		t.Errorf("got %q (ok=%v), want 'A'", r, ok)
+	}
+	if mapper.GlyphOutlineEqual(0xE000, 'B') {
+		t.Error("a contour of a different size should still be rejected")
+	}
+	if !mapper.Config().ContourPermutation {
+		t.Error("Config().ContourPermutation = false")
+	}
+}
+
+func TestGlyphOutlineMapper_NearEqualContourBounds(t *testing.T) {
+	// 两个轮廓的 minX 只差几个单位，都在容差之内，但两个字体中的大小关系相反，排序后的轮廓顺序不同
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 200), triangle(102, 100, 200)}, advance: 1000},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(101, 100, 200), triangle(100, 100, 200)}, advance: 1000},
+	}, map[rune]rune{'A': 1})
+
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapper.SetTolerance(0.5)
+	if _, r, ok := mapper.MappingRune(0xE000); !ok || r != 'A' {
+		t.Errorf("got %q, %v, want 'A', true", r, ok)
+	}
+}
+
+func TestWithContourPermutation_KeepsOrderedMatch(t *testing.T) {
+	// 按顺序对应时两对正方形都在容差之内；贪心对应会让第一个正方形选中更近的第二个，剩下的一对超出容差
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 300), square(116, 100, 300)}, advance: 1000},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(84, 100, 300), square(104, 100, 300)}, advance: 1000},
+	}, map[rune]rune{'A': 1})
+
+	for _, opts := range [][]Option{nil, {WithContourPermutation()}} {
+		mapper, err := NewGlyphOutlineMapper(special, standard, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mapper.SetTolerance(2)
+		if _, r, ok := mapper.MappingRune(0xE000); !ok || r != 'A' {
+			t.Errorf("options %d: got %q, %v, want 'A', true", len(opts), r, ok)
+		}
+	}
+}
+
+func TestSetMatchThreshold_ContourPairing(t *testing.T) {
+	// 按得分判断时，起点不同、轮廓顺序不同的字形与逐点判断时一样按对应之后的偏差计算
+	rotated := square(100, 100, 500)
+	rotated = append(rotated[2:], rotated[:2]...)
+	tests := []struct {
+		name              string
+		upem              int
+		special, standard []testGlyph
+		opts              []Option
+		tolerance         float64 // 0 表示默认容差
+	}{
+		{
+			name:     "start point",
+			upem:     1000,
+			special:  []testGlyph{{contours: [][]testPoint{rotated, triangle(200, 200, 100)}, advance: 800}},
+			standard: []testGlyph{{contours: [][]testPoint{square(100, 100, 500), triangle(200, 200, 100)}, advance: 800}},
+			opts:     []Option{WithStartPointInvariance()},
+		},
+		{
+			name:     "contour permutation",
+			upem:     16384,
+			special:  []testGlyph{{contours: [][]testPoint{square(1001, 1000, 3000), triangle(1000, 6000, 3000)}, advance: 10000}},
+			standard: []testGlyph{{contours: [][]testPoint{square(1000, 1000, 3000), triangle(1001, 6000, 3000)}, advance: 10000}},
+			opts:     []Option{WithContourPermutation()},
+		},
+		{
+			name:      "tied bounds",
+			upem:      1000,
+			special:   []testGlyph{{contours: [][]testPoint{square(100, 100, 200), triangle(102, 100, 200)}, advance: 1000}},
+			standard:  []testGlyph{{contours: [][]testPoint{square(101, 100, 200), triangle(100, 100, 200)}, advance: 1000}},
+			tolerance: 0.5,
+		},
+	}
+	for _, tt := range tests {
+		special := buildTestFont(tt.upem, tt.special, map[rune]rune{0xE000: 1})
+		standard := buildTestFont(tt.upem, tt.standard, map[rune]rune{'A': 1})
+		for _, threshold := range []float64{0.01, 0.3, 0.5} {
+			mapper, err := NewGlyphOutlineMapper(special, standard, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.tolerance > 0 {
+				mapper.SetTolerance(tt.tolerance)
+			}
+			mapper.SetMatchThreshold(threshold)
+			if _, r, ok := mapper.MappingRune(0xE000); !ok || r != 'A' {
+				t.Errorf("%s, threshold %v: got %q, %v, want 'A', true", tt.name, threshold, r, ok)
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package mapper
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxFontSize 是解压 gzip、WOFF 和 zip 中的字体后允许的最大字节数，防止很小的压缩数据解压出巨大的结果，
+// 超过时返回 ErrFontTooLarge。最大的 CJK 字体集合也只有一百多 MB
+var maxFontSize int64 = 256 << 20
+
+// readFontData 读取 r 中的全部数据，超过 maxFontSize 时返回 ErrFontTooLarge
+func readFontData(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxFontSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxFontSize {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrFontTooLarge, maxFontSize)
+	}
+	return data, nil
+}
+
+// decodeFontData 把经过压缩或封装的字体数据还原为 sfnt 格式，目前支持 gzip 和 WOFF 1.0，
+// 其他数据原样返回
+func decodeFontData(data []byte) ([]byte, error) {
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("gunzip font: %w", err)
+		}
+		defer r.Close()
+		if data, err = readFontData(r); err != nil {
+			return nil, fmt.Errorf("gunzip font: %w", err)
+		}
+	}
+	if len(data) >= 4 && binary.BigEndian.Uint32(data) == sfntVersionWOFF {
+		return decodeWOFF(data)
+	}
+	return data, nil
+}
+
+// decodeWOFF 把 WOFF 1.0 字体还原为 sfnt，每个表按需用 zlib 解压
+func decodeWOFF(data []byte) ([]byte, error) {
+	const headerSize, entrySize = 44, 20
+	if len(data) < headerSize {
+		return nil, errors.New("WOFF header is too short")
+	}
+	flavor := binary.BigEndian.Uint32(data[4:])
+	n := int(binary.BigEndian.Uint16(data[12:]))
+	if headerSize+entrySize*n > len(data) {
+		return nil, errors.New("WOFF table directory is too short")
+	}
+
+	type table struct {
+		tag      []byte
+		checksum uint32
+		data     []byte
+	}
+	tables := make([]table, n)
+	var total int64
+	for i := range tables {
+		entry := data[headerSize+entrySize*i:]
+		offset := int64(binary.BigEndian.Uint32(entry[4:]))
+		compLength := int64(binary.BigEndian.Uint32(entry[8:]))
+		origLength := int64(binary.BigEndian.Uint32(entry[12:]))
+		if offset+compLength > int64(len(data)) {
+			return nil, fmt.Errorf("WOFF table %q is out of bounds", entry[:4])
+		}
+		if total += origLength; total > maxFontSize {
+			return nil, fmt.Errorf("%w: WOFF tables exceed %d bytes", ErrFontTooLarge, maxFontSize)
+		}
+		raw := data[offset : offset+compLength]
+		if compLength < origLength {
+			r, err := zlib.NewReader(bytes.NewReader(raw))
+			if err != nil {
+				return nil, fmt.Errorf("inflate WOFF table %q: %w", entry[:4], err)
+			}
+			raw, err = io.ReadAll(io.LimitReader(r, origLength))
+			r.Close()
+			if err != nil {
+				return nil, fmt.Errorf("inflate WOFF table %q: %w", entry[:4], err)
+			}
+		}
+		if int64(len(raw)) != origLength {
+			return nil, fmt.Errorf("WOFF table %q has length %d, want %d", entry[:4], len(raw), origLength)
+		}
+		tables[i] = table{tag: entry[:4], checksum: binary.BigEndian.Uint32(entry[16:]), data: raw}
+	}
+
+	searchRange, entrySelector := 1, 0
+	for searchRange*2 <= n {
+		searchRange *= 2
+		entrySelector++
+	}
+	out := binary.BigEndian.AppendUint32(nil, flavor)
+	out = binary.BigEndian.AppendUint16(out, uint16(n))
+	out = binary.BigEndian.AppendUint16(out, uint16(searchRange*16))
+	out = binary.BigEndian.AppendUint16(out, uint16(entrySelector))
+	out = binary.BigEndian.AppendUint16(out, uint16(n*16-searchRange*16))
+	offset := 12 + 16*n
+	for _, t := range tables {
+		out = append(out, t.tag...)
+		out = binary.BigEndian.AppendUint32(out, t.checksum)
+		out = binary.BigEndian.AppendUint32(out, uint32(offset))
+		out = binary.BigEndian.AppendUint32(out, uint32(len(t.data)))
+		offset += (len(t.data) + 3) &^ 3
+	}
+	for _, t := range tables {
+		out = append(out, t.data...)
+		for len(out)%4 != 0 {
+			out = append(out, 0)
+		}
+	}
+	return out, nil
+}
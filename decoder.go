@@ -0,0 +1,183 @@
+package mapper
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// decodedRune 是缓存的单个字符解码结果
+type decodedRune struct {
+	standard rune
+	ok       bool
+}
+
+// decodeRune 返回特殊字符对应的标准字符，结果会被缓存，没有匹配时原样返回
+func (g *GlyphOutlineMapper) decodeRune(r rune) (rune, bool) {
+	cache, err := g.standardGlyphs(context.Background())
+	if err != nil {
+		return r, false
+	}
+	if v, ok := cache.decoded.Load(r); ok {
+		g.metrics.AddCacheHit()
+		d := v.(decodedRune)
+		return d.standard, d.ok
+	}
+	d := decodedRune{standard: r}
+	if result, ok, _, _ := g.mappingRune(context.Background(), r, false); ok {
+		d = decodedRune{standard: result.Standard, ok: true}
+	}
+	cache.decoded.Store(r, d)
+	return d.standard, d.ok
+}
+
+// MapString 把文本中的特殊字符逐个替换为对应的标准字符，没有匹配的字符保持不变。
+// 每个字符只会查找一次，结果在 mapper 的生命周期内缓存。
+// 通过 WithOutputNormalization 开启后，会对替换后的文本做 Unicode 规范化
+func (g *GlyphOutlineMapper) MapString(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		decoded, _ := g.decodeRune(r)
+		b.WriteRune(decoded)
+	}
+	return g.normalizeOutput(b.String())
+}
+
+// ApplyMapping 用已经得到的映射（例如 Mapping 或 LoadMapping 的结果）替换 s 中的特殊字符，
+// 与 MapString 一样按 WithOutputNormalization 规范化输出，但不会再比较字形
+func (g *GlyphOutlineMapper) ApplyMapping(m Mapping, s string) string {
+	return g.normalizeOutput(m.Apply(s))
+}
+
+// DecodeString 与 MapString 相同，同时返回特殊字体中有字形却没有找到匹配的字符，按码位升序排列且不重复。
+// 特殊字体中不存在的字符（例如混在文本中的普通标点）原样保留，不算作未映射
+func (g *GlyphOutlineMapper) DecodeString(obfuscated string) (string, []rune) {
+	var b strings.Builder
+	b.Grow(len(obfuscated))
+	var unmapped []rune
+	for _, r := range obfuscated {
+		decoded, ok := g.decodeRune(r)
+		if !ok && g.specialFont.Index(r) != 0 {
+			unmapped = append(unmapped, r)
+		}
+		b.WriteRune(decoded)
+	}
+	slices.Sort(unmapped)
+	return g.normalizeOutput(b.String()), slices.Compact(unmapped)
+}
+
+// DecodeBytes 与 DecodeString 相同，但处理 UTF-8 编码的字节
+func (g *GlyphOutlineMapper) DecodeBytes(obfuscated []byte) ([]byte, []rune) {
+	decoded, unmapped := g.DecodeString(string(obfuscated))
+	return []byte(decoded), unmapped
+}
+
+// Encode 是 MapString 的逆操作：把文本中的标准字符替换为特殊字体中轮廓一致的特殊字符，
+// 得到用特殊字体显示时与原文相同的混淆文本，特殊字体中没有对应字形的字符保持不变。
+// 第一次调用时会映射特殊字体中的全部字符，之后复用结果；已经有映射结果时也可以直接使用 Mapping.Reverse().Apply
+func (g *GlyphOutlineMapper) Encode(s string) string {
+	cache, err := g.standardGlyphs(context.Background())
+	if err != nil {
+		return s
+	}
+	cache.encodeOnce.Do(func() {
+		decoded := Mapping{}
+		for _, r := range g.SpecialRunes() {
+			if standard, ok := g.decodeRune(r); ok {
+				decoded[r] = standard
+			}
+		}
+		cache.encoding = decoded.Reverse()
+	})
+	return cache.encoding.Apply(s)
+}
+
+// normalizeOutput 按配置的规范化形式处理解码后的文本
+func (g *GlyphOutlineMapper) normalizeOutput(s string) string {
+	if g.outputNorm == nil {
+		return s
+	}
+	return g.outputNorm.String(s)
+}
+
+// WithOutputNormalization 对 MapString、ApplyMapping 等解码输出做 Unicode 规范化（例如 norm.NFC），默认不做处理，
+// 输出的就是匹配到的原始码位。Mapping.Apply 不知道 mapper 的配置，不受影响，见 Mapping.ApplyNormalized。
+//
+// 注意 CJK 兼容汉字（U+F900–U+FAFF 等）在 Unicode 中带有规范分解，NFC 和 NFKC 都会把它们替换为对应的统一汉字，
+// 因此开启规范化后这部分字符的输出与它们在标准字体中实际匹配到的码位不同
+func WithOutputNormalization(form norm.Form) Option {
+	return func(g *GlyphOutlineMapper) {
+		g.outputNorm = &form
+	}
+}
+
+// Decoder 从底层的 io.Reader 中读取文本，把其中的特殊字符逐个替换为标准字符后输出，
+// 不需要预先建立完整的映射表，也不需要把整个文档读入内存。
+// 被拆分在两次读取之间的多字节字符会被正确拼接，无效的 UTF-8 字节原样输出
+type Decoder struct {
+	r io.Reader
+}
+
+// NewDecoder 返回从 r 读取并解码的 Decoder，字符的查找结果与 MapString 共享缓存。
+// 开启 WithOutputNormalization 时输出同样会被规范化
+func (g *GlyphOutlineMapper) NewDecoder(r io.Reader) *Decoder {
+	var out io.Reader = &runeDecoder{g: g, r: r, buf: make([]byte, 4096)}
+	if g.outputNorm != nil {
+		out = g.outputNorm.Reader(out)
+	}
+	return &Decoder{r: out}
+}
+
+func (d *Decoder) Read(p []byte) (int, error) {
+	return d.r.Read(p)
+}
+
+// runeDecoder 是没有规范化的解码过程，pending 保存尚未凑齐的多字节字符
+type runeDecoder struct {
+	g       *GlyphOutlineMapper
+	r       io.Reader
+	buf     []byte
+	pending []byte
+	out     []byte
+	err     error
+}
+
+func (d *runeDecoder) Read(p []byte) (int, error) {
+	for len(d.out) == 0 && d.err == nil {
+		n, err := d.r.Read(d.buf)
+		d.pending = append(d.pending, d.buf[:n]...)
+		d.err = err
+		d.decodePending(err != nil)
+	}
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	if len(d.out) == 0 && d.err != nil {
+		return n, d.err
+	}
+	return n, nil
+}
+
+// decodePending 解码 pending 中完整的字符，eof 为 true 时不再等待被截断的字符
+func (d *runeDecoder) decodePending(eof bool) {
+	i := 0
+	for i < len(d.pending) {
+		rest := d.pending[i:]
+		if !eof && !utf8.FullRune(rest) {
+			break
+		}
+		r, size := utf8.DecodeRune(rest)
+		if r == utf8.RuneError && size == 1 {
+			d.out = append(d.out, rest[0])
+		} else {
+			decoded, _ := d.g.decodeRune(r)
+			d.out = utf8.AppendRune(d.out, decoded)
+		}
+		i += size
+	}
+	d.pending = append(d.pending[:0], d.pending[i:]...)
+}
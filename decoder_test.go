@@ -0,0 +1,119 @@
+package mapper
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestGlyphOutlineMapper_MapString(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+	// U+F900 是 CJK 兼容汉字，NFC 会把它替换为统一汉字 U+8C48
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{'A': 1, 0xF900: 2})
+
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := mapper.MapString("x\ue000\ue001"), "xA\uf900"; got != want {
+		t.Errorf("MapString = %q, want %q", got, want)
+	}
+
+	normalized, err := NewGlyphOutlineMapper(special, standard, WithOutputNormalization(norm.NFC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := normalized.MapString("\ue000\ue001"), "A\u8c48"; got != want {
+		t.Errorf("normalized MapString = %q, want %q", got, want)
+	}
+	m := normalized.Mapping(0xE000, 0xE001)
+	if got, want := normalized.ApplyMapping(m, "\ue000\ue001"), "A\u8c48"; got != want {
+		t.Errorf("normalized ApplyMapping = %q, want %q", got, want)
+	}
+	if got, want := mapper.ApplyMapping(m, "\ue001"), "\uf900"; got != want {
+		t.Errorf("ApplyMapping = %q, want %q", got, want)
+	}
+	if got, want := m.ApplyNormalized("\ue001", norm.NFC), "\u8c48"; got != want {
+		t.Errorf("ApplyNormalized = %q, want %q", got, want)
+	}
+}
+
+func TestGlyphOutlineMapper_NewDecoder(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'中': 1})
+
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 逐字节读取，每个多字节字符都会被拆分在多次读取之间；末尾是一个被截断的字符
+	input := "a\ue000b\ue000\xff" + "\ue000"[:2]
+	got, err := io.ReadAll(mapper.NewDecoder(iotest.OneByteReader(strings.NewReader(input))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a中b中\xff" + "\ue000"[:2]; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestGlyphOutlineMapper_DecodeString(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, unmapped := mapper.DecodeString("\ue001x\ue000\ue001")
+	if want := "\ue001xA\ue001"; got != want {
+		t.Errorf("DecodeString = %q, want %q", got, want)
+	}
+	if len(unmapped) != 1 || unmapped[0] != 0xE001 {
+		t.Errorf("unmapped = %U, want [U+E001]", unmapped)
+	}
+	if b, _ := mapper.DecodeBytes([]byte("\ue000")); string(b) != "A" {
+		t.Errorf("DecodeBytes = %q, want \"A\"", b)
+	}
+}
+
+func TestGlyphOutlineMapper_Encode(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded := mapper.Encode("BAC")
+	if want := "\ue000\ue001C"; encoded != want {
+		t.Errorf("Encode = %q, want %q", encoded, want)
+	}
+	if decoded := mapper.MapString(encoded); decoded != "BAC" {
+		t.Errorf("MapString(Encode) = %q, want \"BAC\"", decoded)
+	}
+}
@@ -0,0 +1,71 @@
+package mapper
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// MappingEntry 是映射中的一条记录
+type MappingEntry struct {
+	Special  rune `json:"special"`
+	Standard rune `json:"standard"`
+}
+
+// MappingChange 表示同一个特殊字符在两次映射中对应了不同的标准字符
+type MappingChange struct {
+	Special rune `json:"special"`
+	Old     rune `json:"old"`
+	New     rune `json:"new"`
+}
+
+// MappingDiff 是两次映射结果之间的差异，各列表均按特殊字符升序排列
+type MappingDiff struct {
+	Added   []MappingEntry  `json:"added"`   // 只出现在新映射中的记录
+	Removed []MappingEntry  `json:"removed"` // 只出现在旧映射中的记录
+	Changed []MappingChange `json:"changed"` // 两边都有但标准字符不同的记录
+}
+
+// DiffMappings 比较两次映射结果，常用于评估调整容差或更换标准字体后的效果
+func DiffMappings(old, new map[rune]rune) MappingDiff {
+	diff := MappingDiff{}
+	for special, standard := range new {
+		oldStandard, exists := old[special]
+		switch {
+		case !exists:
+			diff.Added = append(diff.Added, MappingEntry{Special: special, Standard: standard})
+		case oldStandard != standard:
+			diff.Changed = append(diff.Changed, MappingChange{Special: special, Old: oldStandard, New: standard})
+		}
+	}
+	for special, standard := range old {
+		if _, exists := new[special]; !exists {
+			diff.Removed = append(diff.Removed, MappingEntry{Special: special, Standard: standard})
+		}
+	}
+	byEntry := func(a, b MappingEntry) int { return int(a.Special - b.Special) }
+	slices.SortFunc(diff.Added, byEntry)
+	slices.SortFunc(diff.Removed, byEntry)
+	slices.SortFunc(diff.Changed, func(a, b MappingChange) int { return int(a.Special - b.Special) })
+	return diff
+}
+
+// Empty 判断两次映射是否完全一致
+func (d MappingDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// String 以每行一条记录的形式输出差异，新增、删除、变化分别以 +、-、~ 开头
+func (d MappingDiff) String() string {
+	var b strings.Builder
+	for _, e := range d.Added {
+		fmt.Fprintf(&b, "+ %U => %U %q\n", e.Special, e.Standard, e.Standard)
+	}
+	for _, e := range d.Removed {
+		fmt.Fprintf(&b, "- %U => %U %q\n", e.Special, e.Standard, e.Standard)
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(&b, "~ %U => %U %q -> %U %q\n", c.Special, c.Old, c.Old, c.New, c.New)
+	}
+	return b.String()
+}
@@ -0,0 +1,17 @@
+package mapper
+
+import "testing"
+
+func TestDiffMappings(t *testing.T) {
+	old := map[rune]rune{0xE000: '一', 0xE001: '二', 0xE002: '三'}
+	new := map[rune]rune{0xE000: '一', 0xE002: '四', 0xE003: '五'}
+
+	diff := DiffMappings(old, new)
+	want := "+ U+E003 => U+4E94 '五'\n- U+E001 => U+4E8C '二'\n~ U+E002 => U+4E09 '三' -> U+56DB '四'\n"
+	if got := diff.String(); got != want {
+		t.Errorf("diff =\n%s\nwant\n%s", got, want)
+	}
+	if diff.Empty() || !DiffMappings(old, old).Empty() {
+		t.Error("Empty reported the wrong result")
+	}
+}
@@ -0,0 +1,131 @@
+package mapper
+
+import (
+	"math"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// distanceSamples 是计算点集距离时每个字形沿轮廓采样的点数（按周长分配给各个轮廓）
+const distanceSamples = 256
+
+// HausdorffMatcher 返回按 Hausdorff 距离比较的 GlyphMatcher：两个字形的轮廓展开为折线后，
+// 任意一个字形轮廓上的每一点到另一个字形轮廓的最近距离都不超过 tolerance 时认为一致。
+// 它只比较轮廓经过的位置，与点的数量、分布和曲线的编码方式无关；距离换算为偏差后体现在 MappingResult.Score 中。
+// tolerance 与 WithTolerance 的单位相同，是按比较尺寸加载后的 26.6 定点数
+func HausdorffMatcher(tolerance fixed.Int26_6) GlyphMatcher {
+	return distanceMatcher(tolerance, func(ab, ba []float64) float64 {
+		return max(maxOf(ab), maxOf(ba))
+	})
+}
+
+// ChamferMatcher 与 HausdorffMatcher 相同，但使用两个方向最近距离的平均值（Chamfer 距离），
+// 对个别离群的点更宽容，适合轮廓大体一致、局部有细微改动的字形
+func ChamferMatcher(tolerance fixed.Int26_6) GlyphMatcher {
+	return distanceMatcher(tolerance, func(ab, ba []float64) float64 {
+		return (meanOf(ab) + meanOf(ba)) / 2
+	})
+}
+
+// distanceMatcher 计算两个方向上每个采样点到另一个字形轮廓的最近距离，再由 reduce 合并为一个距离
+func distanceMatcher(tolerance fixed.Int26_6, reduce func(ab, ba []float64) float64) GlyphMatcher {
+	return GlyphMatcherFunc(func(special, standard GlyphData) (bool, float64) {
+		if len(special.Points) == 0 || len(standard.Points) == 0 {
+			if len(special.Points) != len(standard.Points) {
+				return false, math.Inf(1)
+			}
+			return true, 0
+		}
+		a, b := glyphPolylines(special), glyphPolylines(standard)
+		distance := reduce(nearestDistances(samplePolylines(a), b), nearestDistances(samplePolylines(b), a))
+		if distance > float64(tolerance) {
+			return false, math.Inf(1)
+		}
+		return true, relativeDeviation(distance, 1, float64(tolerance))
+	})
+}
+
+// glyphPolylines 把字形的每个轮廓展开为闭合折线，精度取边界框较长一边的千分之一
+func glyphPolylines(glyph GlyphData) [][]vec {
+	bounds := glyphBounds(glyph, [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)})
+	flatness := max(bounds[2]-bounds[0], bounds[3]-bounds[1], 1) / 1000
+	polys := make([][]vec, 0, len(glyph.Ends))
+	start := 0
+	for _, end := range glyph.Ends {
+		if poly := flattenContour(glyph.Points[start:end], flatness); len(poly) > 0 {
+			polys = append(polys, poly)
+		}
+		start = end
+	}
+	return polys
+}
+
+// samplePolylines 沿弧长在全部折线上均匀采样约 distanceSamples 个点，每个轮廓至少保留一个点
+func samplePolylines(polys [][]vec) []vec {
+	perimeters := make([]float64, len(polys))
+	var total float64
+	for i, poly := range polys {
+		for j := range poly {
+			perimeters[i] += poly[(j+1)%len(poly)].sub(poly[j]).len()
+		}
+		total += perimeters[i]
+	}
+	var samples []vec
+	for i, poly := range polys {
+		n := 1
+		if total > 0 {
+			n = max(int(math.Round(distanceSamples*perimeters[i]/total)), 1)
+		}
+		if perimeters[i] == 0 {
+			samples = append(samples, poly[0])
+			continue
+		}
+		samples = append(samples, resampleContour(poly, perimeters[i], n)...)
+	}
+	return samples
+}
+
+// nearestDistances 返回每个采样点到 polys 中最近线段的距离
+func nearestDistances(samples []vec, polys [][]vec) []float64 {
+	distances := make([]float64, len(samples))
+	for i, p := range samples {
+		nearest := math.Inf(1)
+		for _, poly := range polys {
+			for j := range poly {
+				nearest = min(nearest, segmentDistance(p, poly[j], poly[(j+1)%len(poly)]))
+			}
+		}
+		distances[i] = nearest
+	}
+	return distances
+}
+
+// segmentDistance 返回点 p 到线段 ab 的距离
+func segmentDistance(p, a, b vec) float64 {
+	ab, ap := b.sub(a), p.sub(a)
+	l2 := ab.x*ab.x + ab.y*ab.y
+	if l2 == 0 {
+		return ap.len()
+	}
+	t := min(max((ap.x*ab.x+ap.y*ab.y)/l2, 0), 1)
+	return p.sub(vec{a.x + ab.x*t, a.y + ab.y*t}).len()
+}
+
+func maxOf(values []float64) float64 {
+	m := 0.0
+	for _, v := range values {
+		m = max(m, v)
+	}
+	return m
+}
+
+func meanOf(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
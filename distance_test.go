@@ -0,0 +1,45 @@
+package mapper
+
+import (
+	"testing"
+
+	"golang.org/x/image/math/fixed"
+)
+
+func TestHausdorffMatcher(t *testing.T) {
+	// 特殊字形是向右平移了 2 个单位、边上多了中点的正方形
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{{{102, 100, false}, {102, 350, false}, {102, 600, false}, {602, 600, false}, {602, 100, false}, {352, 100, false}}}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 510)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2, 'O': 3})
+
+	for name, matcher := range map[string]GlyphMatcher{
+		"hausdorff": HausdorffMatcher(fixed.I(5)),
+		"chamfer":   ChamferMatcher(fixed.I(5)),
+	} {
+		mapper, err := NewGlyphOutlineMapper(special, standard, WithMatchers(matcher), WithMatchStrategy(BestMatch))
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, ok := mapper.MappingRuneResult(0xE000)
+		if !ok || result.Standard != 'O' {
+			t.Fatalf("%s: got %+v (ok=%v), want 'O'", name, result, ok)
+		}
+		// 最远 2 个单位的偏移，在 5 个单位的容差下得分不会低于 1/(1+0.4)
+		if result.Score < 0.7 || result.Score >= 1 {
+			t.Errorf("%s: score %v, want in [0.7, 1)", name, result.Score)
+		}
+	}
+
+	strict, err := NewGlyphOutlineMapper(special, standard, WithMatchers(HausdorffMatcher(fixed.I(1))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, standardRune, ok := strict.MappingRune(0xE000); ok {
+		t.Errorf("matched %q with a tolerance below the offset", standardRune)
+	}
+}
@@ -0,0 +1,51 @@
+package mapper
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+)
+
+// FindDuplicateGlyphs 找出字体中轮廓完全相同的字符，每组按码位升序排列，各组按第一个字符排序。
+// 混淆字体经常把许多码位指向同一个字形，知道这些等价类之后每组只需要映射一个字符。
+// 只有一个字符的组和没有轮廓的空白字形不会出现在结果中
+func FindDuplicateGlyphs(fontData []byte) ([][]rune, error) {
+	f, err := parseFont(fontData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFontParse, err)
+	}
+	g := newGlyphOutlineMapperWithLastRune(f.source, f.source, 0)
+	groups := map[string][]rune{}
+	for _, r := range f.source.Runes() {
+		o, err := g.loadGlyph(f.source, f.source.Index(r), g.scale)
+		if err != nil || len(o.points) == 0 {
+			continue
+		}
+		key := outlineKey(o)
+		groups[key] = append(groups[key], r)
+	}
+
+	var duplicates [][]rune
+	for _, runes := range groups {
+		if len(runes) > 1 {
+			duplicates = append(duplicates, runes)
+		}
+	}
+	slices.SortFunc(duplicates, func(a, b []rune) int { return int(a[0] - b[0]) })
+	return duplicates, nil
+}
+
+// outlineKey 把轮廓编码为可以作为 map 键的字符串，轮廓相同当且仅当键相同
+func outlineKey(o *outline) string {
+	b := make([]byte, 0, len(o.ends)*2+len(o.points)*5)
+	b = binary.AppendUvarint(b, uint64(len(o.ends)))
+	for _, end := range o.ends {
+		b = binary.AppendUvarint(b, uint64(end))
+	}
+	for _, p := range o.points {
+		b = binary.AppendVarint(b, int64(p.X))
+		b = binary.AppendVarint(b, int64(p.Y))
+		b = append(b, byte(p.Flags&1))
+	}
+	return string(b)
+}
@@ -0,0 +1,30 @@
+package mapper
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestFindDuplicateGlyphs(t *testing.T) {
+	// 字形 1 和 3 的轮廓相同但索引不同，字形 2 只被一个码位使用，字形 4 是空白字形
+	font := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{advance: 500},
+	}, map[rune]rune{0xE003: 1, 0xE001: 1, 0xE002: 3, 0xE000: 2, 0xE010: 4, 0xE011: 4})
+
+	got, err := FindDuplicateGlyphs(font)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]rune{{0xE001, 0xE002, 0xE003}}
+	if len(got) != len(want) || !slices.Equal(got[0], want[0]) {
+		t.Errorf("got %U, want %U", got, want)
+	}
+
+	if _, err := FindDuplicateGlyphs([]byte("not a font")); !errors.Is(err, ErrFontParse) {
+		t.Errorf("got %v, want ErrFontParse", err)
+	}
+}
@@ -0,0 +1,95 @@
+package mapper
+
+import (
+	"image"
+	"math"
+	"sync"
+)
+
+// GlyphEmbedder 把渲染好的单个字形转换为特征向量，例如用 ONNX Runtime 运行一个小型的字符嵌入模型。
+// 同一个 embedder 返回的向量长度必须相同，实现需要可以并发调用。
+//
+// 本包及其子包都不提供 ONNX Runtime 的实现：它的 Go 绑定需要 cgo 和 onnxruntime 的动态库。
+// 用 github.com/yalue/onnxruntime_go 实现时，把 img 的灰度值按行写入形状为 1×1×size×size 的 float32 输入张量，
+// 用 NewAdvancedSession 加载模型，每次 Run 之后从输出张量的 GetData 取出向量复制返回；
+// 绑定了输入输出张量的会话不能并发运行，需要加锁或者为每个 goroutine 各建一个会话
+type GlyphEmbedder interface {
+	Embed(img image.Image) ([]float32, error)
+}
+
+// GlyphEmbedderFunc 让普通函数实现 GlyphEmbedder
+type GlyphEmbedderFunc func(img image.Image) ([]float32, error)
+
+func (f GlyphEmbedderFunc) Embed(img image.Image) ([]float32, error) {
+	return f(img)
+}
+
+// EmbeddingMatcher 返回按嵌入向量比较的 GlyphMatcher：两个字形分别渲染为 size×size 的白底黑字图像（与 WithOCRFallback 相同），
+// 交给 embedder 得到向量，余弦相似度不低于 threshold 时认为一致，偏差换算回得分时就是相似度。
+// 混淆时略微改画了笔画的字形所有几何比较都无法匹配，只能这样比较。每个字形的向量只计算一次并缓存在 matcher 中，
+// embedder 出错的字形不匹配。通过 WithMatchers(EmbeddingMatcher(embedder, 64, 0.9)) 使用，size 小于等于 0 时使用 64
+func EmbeddingMatcher(embedder GlyphEmbedder, size int, threshold float64) GlyphMatcher {
+	if size <= 0 {
+		size = 64
+	}
+	cache := &embeddingCache{embedder: embedder, size: size}
+	return GlyphMatcherFunc(func(special, standard GlyphData) (bool, float64) {
+		a, b := cache.embedding(special), cache.embedding(standard)
+		if a == nil || b == nil {
+			return false, math.Inf(1)
+		}
+		similarity := cosineSimilarity(a, b)
+		if similarity < threshold || similarity <= 0 {
+			return false, math.Inf(1)
+		}
+		return true, 1/similarity - 1
+	})
+}
+
+// embeddingKey 区分缓存的字形：同一个字符经过 WithTransforms 变换后轮廓不同，需要按轮廓的哈希区分
+type embeddingKey struct {
+	font string
+	r    rune
+	hash uint64
+}
+
+// embeddingCache 缓存每个字形的嵌入向量，出错的字形记为 nil，不会重复调用 embedder
+type embeddingCache struct {
+	embedder GlyphEmbedder
+	size     int
+	vectors  sync.Map // embeddingKey => []float32
+}
+
+func (c *embeddingCache) embedding(glyph GlyphData) []float32 {
+	o := &outline{points: glyph.Points, ends: glyph.Ends}
+	key := embeddingKey{font: glyph.Font, r: glyph.Rune, hash: outlineHash(o, 1)}
+	if v, ok := c.vectors.Load(key); ok {
+		return v.([]float32)
+	}
+	var vector []float32
+	if len(glyph.Points) > 0 {
+		if v, err := c.embedder.Embed(ocrImage(glyph, c.size)); err == nil && len(v) > 0 {
+			vector = v
+		}
+	}
+	v, _ := c.vectors.LoadOrStore(key, vector)
+	return v.([]float32)
+}
+
+// cosineSimilarity 返回两个向量的余弦相似度，长度不同或有零向量时返回 0
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		x, y := float64(a[i]), float64(b[i])
+		dot += x * y
+		na += x * x
+		nb += y * y
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
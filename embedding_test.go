@@ -0,0 +1,53 @@
+package mapper
+
+import (
+	"image"
+	"image/color"
+	"sync/atomic"
+	"testing"
+)
+
+func TestEmbeddingMatcher(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		// 比标准字形多一个点，逐点比较无法匹配
+		{contours: [][]testPoint{{{100, 0, false}, {350, 500, false}, {600, 0, false}, {350, 0, false}}}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+
+	// 把图像缩小为 8×8 的灰度作为嵌入向量
+	var calls atomic.Int32
+	embedder := GlyphEmbedderFunc(func(img image.Image) ([]float32, error) {
+		calls.Add(1)
+		b := img.Bounds()
+		v := make([]float32, 64)
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				c := color.GrayModel.Convert(img.At(x, y)).(color.Gray)
+				v[(y-b.Min.Y)*8/b.Dy()*8+(x-b.Min.X)*8/b.Dx()] += float32(0xff - c.Y)
+			}
+		}
+		return v, nil
+	})
+	matcher := EmbeddingMatcher(embedder, 32, 0.95)
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithMatchers(matcher))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, r, ok := mapper.MappingRune(0xE000); !ok || r != 'B' {
+		t.Errorf("got %q (ok=%v), want 'B'", r, ok)
+	}
+	before := calls.Load()
+	if !mapper.GlyphOutlineEqual(0xE000, 'B') {
+		t.Error("GlyphOutlineEqual = false, want true")
+	}
+	if calls.Load() != before {
+		t.Errorf("embedder called %d more times for glyphs already embedded", calls.Load()-before)
+	}
+
+	if got := cosineSimilarity([]float32{1, 0}, []float32{1, 0, 0}); got != 0 {
+		t.Errorf("similarity of vectors with different lengths = %v, want 0", got)
+	}
+}
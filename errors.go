@@ -0,0 +1,57 @@
+package mapper
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrFontParse 表示字体解析失败，ErrSpecialFontParse 和 ErrStandardFontParse 都可以用 errors.Is 匹配到它
+	ErrFontParse = errors.New("parse font failed")
+	// ErrSpecialFontParse 表示特殊字体解析失败
+	ErrSpecialFontParse error = fontParseError("parse special font failed")
+	// ErrStandardFontParse 表示标准字体解析失败
+	ErrStandardFontParse error = fontParseError("parse standard font failed")
+	// ErrUnsupportedFormat 表示字体格式不受支持，例如 CFF2 轮廓的 OpenType 或 WOFF2
+	ErrUnsupportedFormat = errors.New("unsupported font format")
+	// ErrFontTooLarge 表示 gzip、WOFF 或 zip 中的字体解压后过大
+	ErrFontTooLarge = errors.New("decompressed font is too large")
+	// ErrNoGlyfTable 表示字体中既没有 glyf 表也没有 CFF 表，无法读取轮廓
+	ErrNoGlyfTable = errors.New("font has no glyf table")
+	// ErrIdenticalFonts 表示特殊字体和标准字体是同一个字体，通常是传错了参数
+	ErrIdenticalFonts = errors.New("special and standard fonts are identical")
+	// ErrCompareTimeout 表示单个字符的比较超过了 WithDeadline 设置的时间
+	ErrCompareTimeout = errors.New("compare timed out")
+	// ErrGlyphLoad 表示字形数据损坏、无法加载，所有的 *GlyphLoadError 都可以用 errors.Is 匹配到它
+	ErrGlyphLoad = errors.New("load glyph failed")
+	// ErrNoGlyph 表示字符在字体中没有字形
+	ErrNoGlyph = errors.New("no glyph")
+	// ErrNoMatch 表示标准字体中没有与特殊字符轮廓一致的字符
+	ErrNoMatch = errors.New("no matching glyph")
+)
+
+// fontParseError 是两个字体解析失败的错误类型，使它们都能匹配 ErrFontParse
+type fontParseError string
+
+func (e fontParseError) Error() string {
+	return string(e)
+}
+
+func (e fontParseError) Is(target error) bool {
+	return target == ErrFontParse
+}
+
+// RuneError 给单个字符的映射失败附加字符和字体的上下文，Err 通常是 ErrNoGlyph、ErrNoMatch、ErrCompareTimeout 或 *GlyphLoadError
+type RuneError struct {
+	Rune rune
+	Font string // 出错的字体，"special"、"standard" 或 AddStandardFont 时指定的名字
+	Err  error
+}
+
+func (e *RuneError) Error() string {
+	return fmt.Sprintf("map %s rune %U: %v", e.Font, e.Rune, e.Err)
+}
+
+func (e *RuneError) Unwrap() error {
+	return e.Err
+}
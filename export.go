@@ -0,0 +1,52 @@
+package mapper
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// exportEntry 是导出为 JSON 时单条映射的格式
+type exportEntry struct {
+	Special  string   `json:"special"`
+	Standard string   `json:"standard"`
+	Score    *float64 `json:"score,omitempty"`
+}
+
+// ExportJSON 把映射结果以 JSON 数组写入 w，includeScore 为 true 时包含每条结果的得分
+func ExportJSON(w io.Writer, results []MappingResult, includeScore bool) error {
+	entries := make([]exportEntry, len(results))
+	for i, result := range results {
+		entries[i] = exportEntry{Special: string(result.Special), Standard: string(result.Standard)}
+		if includeScore {
+			entries[i].Score = &results[i].Score
+		}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(entries)
+}
+
+// ExportCSV 把映射结果以 CSV 写入 w，第一行为表头，includeScore 为 true 时增加 score 列
+func ExportCSV(w io.Writer, results []MappingResult, includeScore bool) error {
+	writer := csv.NewWriter(w)
+	header := []string{"special", "standard"}
+	if includeScore {
+		header = append(header, "score")
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, result := range results {
+		record := []string{string(result.Special), string(result.Standard)}
+		if includeScore {
+			record = append(record, strconv.FormatFloat(result.Score, 'f', -1, 64))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
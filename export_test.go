@@ -0,0 +1,58 @@
+package mapper
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGlyphOutlineMapper_MappingDetailedScore(t *testing.T) {
+	special := buildTestFont(16384, []testGlyph{
+		{contours: [][]testPoint{square(1000, 1000, 8000)}, advance: 10000},
+		{contours: [][]testPoint{triangle(1000, 1000, 8000)}, advance: 10000},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+	standard := buildTestFont(16384, []testGlyph{
+		{contours: [][]testPoint{square(1000, 1000, 8000)}, advance: 10000},
+		{contours: [][]testPoint{triangle(1000, 1000, 8002)}, advance: 10000},
+	}, map[rune]rune{'A': 1, 'B': 2})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := mapper.MappingDetailed(0xE000, 0xE001)
+	if len(results) != 2 || results[0].Standard != 'A' || results[1].Standard != 'B' {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if results[0].Score != 1 {
+		t.Errorf("exact match score = %v, want 1", results[0].Score)
+	}
+	if results[1].Score <= 0.5 || results[1].Score >= 1 {
+		t.Errorf("near match score = %v, want within (0.5, 1)", results[1].Score)
+	}
+	if similarity, err := mapper.GlyphSimilarity(0xE001, 'B'); err != nil || similarity != results[1].Score {
+		t.Errorf("GlyphSimilarity = %v, %v; want %v", similarity, err, results[1].Score)
+	}
+	if similarity, _ := mapper.GlyphSimilarity(0xE001, 'A'); similarity != 0 {
+		t.Errorf("GlyphSimilarity of different shapes = %v, want 0", similarity)
+	}
+}
+
+func TestExport(t *testing.T) {
+	results := []MappingResult{{Special: 0xE000, Standard: '的', Score: 1}, {Special: 0xE001, Standard: '一', Score: 0.75}}
+
+	var buf bytes.Buffer
+	if err := ExportCSV(&buf, results, true); err != nil {
+		t.Fatal(err)
+	}
+	if want := "special,standard,score\n\ue000,的,1\n\ue001,一,0.75\n"; buf.String() != want {
+		t.Errorf("ExportCSV = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := ExportJSON(&buf, results[:1], false); err != nil {
+		t.Fatal(err)
+	}
+	if want := "[\n  {\n    \"special\": \"\ue000\",\n    \"standard\": \"的\"\n  }\n]\n"; buf.String() != want {
+		t.Errorf("ExportJSON = %q, want %q", buf.String(), want)
+	}
+}
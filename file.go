@@ -0,0 +1,33 @@
+package mapper
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// NewGlyphOutlineMapperFromFiles 从磁盘读取两个字体文件，字体可以是 TTF、WOFF 或 gzip 压缩后的数据
+func NewGlyphOutlineMapperFromFiles(specialPath, standardPath string, opts ...Option) (*GlyphOutlineMapper, error) {
+	specialFontData, err := os.ReadFile(specialPath)
+	if err != nil {
+		return nil, fmt.Errorf("read special font failed: %w", err)
+	}
+	standardFontData, err := os.ReadFile(standardPath)
+	if err != nil {
+		return nil, fmt.Errorf("read standard font failed: %w", err)
+	}
+	return NewGlyphOutlineMapper(specialFontData, standardFontData, opts...)
+}
+
+// NewGlyphOutlineMapperFromReaders 读取 special 和 standard 中的全部数据作为两个字体
+func NewGlyphOutlineMapperFromReaders(special, standard io.Reader, opts ...Option) (*GlyphOutlineMapper, error) {
+	specialFontData, err := io.ReadAll(special)
+	if err != nil {
+		return nil, fmt.Errorf("read special font failed: %w", err)
+	}
+	standardFontData, err := io.ReadAll(standard)
+	if err != nil {
+		return nil, fmt.Errorf("read standard font failed: %w", err)
+	}
+	return NewGlyphOutlineMapper(specialFontData, standardFontData, opts...)
+}
@@ -0,0 +1,62 @@
+package mapper
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestNewGlyphOutlineMapperFromFiles(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1})
+
+	dir := t.TempDir()
+	specialPath, standardPath := filepath.Join(dir, "special.ttf"), filepath.Join(dir, "standard.ttf")
+	if err := os.WriteFile(specialPath, special, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(standardPath, standard, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mapper, err := NewGlyphOutlineMapperFromFiles(specialPath, standardPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, standardRune, ok := mapper.MappingRune(0xE000); !ok || standardRune != 'A' {
+		t.Fatalf("got %q (ok=%v), want 'A'", standardRune, ok)
+	}
+
+	_, err = NewGlyphOutlineMapperFromFiles(specialPath, filepath.Join(dir, "missing.ttf"))
+	if !errors.Is(err, fs.ErrNotExist) || !strings.Contains(err.Error(), "standard font") {
+		t.Errorf("missing file error = %v, want fs.ErrNotExist naming the standard font", err)
+	}
+}
+
+func TestNewGlyphOutlineMapperFromReaders(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1})
+
+	if _, err := NewGlyphOutlineMapperFromReaders(bytes.NewReader(special), bytes.NewReader(standard)); err != nil {
+		t.Fatal(err)
+	}
+
+	broken := errors.New("connection reset")
+	_, err := NewGlyphOutlineMapperFromReaders(iotest.ErrReader(broken), bytes.NewReader(standard))
+	if !errors.Is(err, broken) || !strings.Contains(err.Error(), "special font") {
+		t.Errorf("read error = %v, want %v naming the special font", err, broken)
+	}
+}
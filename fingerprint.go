@@ -0,0 +1,72 @@
+package mapper
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/math/fixed"
+)
+
+// GlyphFingerprint 是字形轮廓的紧凑摘要，可以直接比较或作为 map 的键，
+// 用于在外部建立自己的字形索引、给字体去重或者分发指纹数据库。
+// 坐标按 1/1000 em 取整，与字体的 unitsPerEm 无关；轮廓顺序与比较时相同，不受复合字形组件顺序影响
+type GlyphFingerprint struct {
+	Contours int    // 轮廓数量
+	Points   int    // 全部轮廓的点数
+	Bounds   [4]int // 边界框 minX、minY、maxX、maxY
+	Hash     uint64 // 每个轮廓的点数以及取整后的点坐标和曲线标记的 FNV-1a 哈希
+
+	Moments [7]float64 // 填充后字形的 Hu 不变矩，与平移、缩放和旋转无关，见 HuMomentMatcher
+}
+
+// Fingerprint 返回字体中字符 r 的字形指纹。字体解析失败时返回的错误可以用 errors.Is 匹配 ErrFontParse，
+// 字符没有字形时匹配 ErrNoGlyph，字形损坏时为 *GlyphLoadError
+func Fingerprint(fontData []byte, r rune) (GlyphFingerprint, error) {
+	f, err := parseFont(fontData)
+	if err != nil {
+		return GlyphFingerprint{}, fmt.Errorf("%w: %w", ErrFontParse, err)
+	}
+	has, err := f.source.Has(r)
+	if err != nil {
+		return GlyphFingerprint{}, &GlyphLoadError{Font: "font", Rune: r, Index: truetype.Index(f.source.Index(r)), Err: err}
+	}
+	if !has {
+		return GlyphFingerprint{}, fmt.Errorf("%U: %w", r, ErrNoGlyph)
+	}
+	g := newGlyphOutlineMapperWithLastRune(f.source, f.source, 0)
+	glyph, loadErr := g.loadCachedGlyph(f.source, "font", r)
+	if loadErr != nil {
+		return GlyphFingerprint{}, loadErr
+	}
+	return outlineFingerprint(glyph.outline), nil
+}
+
+// outlineFingerprint 计算按默认的 1000 ppem 加载的轮廓的指纹
+func outlineFingerprint(o *outline) GlyphFingerprint {
+	fp := GlyphFingerprint{Contours: len(o.ends), Points: len(o.points)}
+	h := fnv.New64a()
+	var b []byte
+	start := 0
+	for _, end := range o.ends {
+		b = binary.AppendUvarint(b[:0], uint64(end-start))
+		h.Write(b)
+		start = end
+	}
+	for i, p := range o.points {
+		x, y := fixed.Int26_6(p.X).Round(), fixed.Int26_6(p.Y).Round()
+		if i == 0 {
+			fp.Bounds = [4]int{x, y, x, y}
+		} else {
+			fp.Bounds = [4]int{min(fp.Bounds[0], x), min(fp.Bounds[1], y), max(fp.Bounds[2], x), max(fp.Bounds[3], y)}
+		}
+		b = binary.AppendVarint(b[:0], int64(x))
+		b = binary.AppendVarint(b, int64(y))
+		b = append(b, byte(p.Flags&1))
+		h.Write(b)
+	}
+	fp.Hash = h.Sum64()
+	fp.Moments = huMoments(o.points, o.ends)
+	return fp
+}
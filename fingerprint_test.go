@@ -0,0 +1,39 @@
+package mapper
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	// 不同 unitsPerEm 下相同的设计应当得到相同的指纹
+	small := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 510)}, advance: 800},
+		{advance: 500},
+	}, map[rune]rune{'A': 1, 'B': 2, ' ': 3})
+	large := buildTestFont(2000, []testGlyph{
+		{contours: [][]testPoint{square(200, 200, 1000)}, advance: 1600},
+	}, map[rune]rune{0xE000: 1})
+
+	a, err := Fingerprint(small, 'A')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Contours != 1 || a.Points != 4 || a.Bounds != [4]int{100, 100, 600, 600} {
+		t.Errorf("unexpected fingerprint %+v", a)
+	}
+	if scaled, err := Fingerprint(large, 0xE000); err != nil || scaled != a {
+		t.Errorf("got %+v, %v, want %+v", scaled, err, a)
+	}
+	if b, err := Fingerprint(small, 'B'); err != nil || b.Hash == a.Hash {
+		t.Errorf("different outlines share a fingerprint: %+v, %v", b, err)
+	}
+
+	if _, err := Fingerprint(small, 'Z'); !errors.Is(err, ErrNoGlyph) {
+		t.Errorf("got %v, want ErrNoGlyph", err)
+	}
+	if _, err := Fingerprint([]byte("not a font"), 'A'); !errors.Is(err, ErrFontParse) {
+		t.Errorf("got %v, want ErrFontParse", err)
+	}
+}
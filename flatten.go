@@ -0,0 +1,95 @@
+package mapper
+
+import (
+	"math"
+
+	"github.com/golang/freetype/truetype"
+)
+
+// defaultFlatness 是默认的曲线展开精度：以 1000 单位的 em 计，折线与曲线的偏差不超过 1 个单位（0.1% em）。
+// 0.5 左右可以区分非常相近的字形，2~5 可以明显减少大批量近似匹配的计算量
+const defaultFlatness = 1.0
+
+// maxCurveSegments 限制单段曲线最多展开成的线段数，避免 flatness 过小时点数失控
+const maxCurveSegments = 64
+
+type vec struct {
+	x, y float64
+}
+
+func (v vec) sub(o vec) vec {
+	return vec{v.x - o.x, v.y - o.y}
+}
+
+func (v vec) len() float64 {
+	return math.Hypot(v.x, v.y)
+}
+
+// flattenContour 把一个闭合轮廓展开为折线，折线与曲线之间的最大偏差不超过 flatness（与点坐标同单位）
+func flattenContour(points []truetype.Point, flatness float64) []vec {
+	n := len(points)
+	if n == 0 {
+		return nil
+	}
+	at := func(i int) vec { return vec{float64(points[i%n].X), float64(points[i%n].Y)} }
+	on := func(i int) bool { return points[i%n].Flags&0x01 != 0 }
+	mid := func(a, b vec) vec { return vec{(a.x + b.x) / 2, (a.y + b.y) / 2} }
+
+	// 从一个曲线上的点开始；全部是控制点时，以最后一个和第一个控制点的隐含中点为起点
+	first, start := 0, mid(at(n-1), at(0))
+	rest := n
+	for i := 0; i < n; i++ {
+		if on(i) {
+			first, start, rest = i+1, at(i), n-1
+			break
+		}
+	}
+
+	poly := []vec{start}
+	cur := start
+	for k := 0; k < rest; k++ {
+		i := first + k
+		if on(i) {
+			poly = append(poly, at(i))
+			cur = at(i)
+			continue
+		}
+		// 控制点，曲线终点是下一个曲线上的点、两个控制点的隐含中点或者起点
+		end := start
+		if k+1 < rest {
+			if on(i + 1) {
+				end = at(i + 1)
+				k++
+			} else {
+				end = mid(at(i), at(i+1))
+			}
+		}
+		ctrl := at(i)
+		n := curveSegments(cur, ctrl, end, flatness)
+		for s := 1; s <= n; s++ {
+			t := float64(s) / float64(n)
+			u := 1 - t
+			poly = append(poly, vec{
+				u*u*cur.x + 2*u*t*ctrl.x + t*t*end.x,
+				u*u*cur.y + 2*u*t*ctrl.y + t*t*end.y,
+			})
+		}
+		cur = end
+	}
+	// 闭合轮廓的终点与起点重合，去掉重复的点
+	if len(poly) > 1 && poly[len(poly)-1].sub(poly[0]).len() < 1e-9 {
+		poly = poly[:len(poly)-1]
+	}
+	return poly
+}
+
+// curveSegments 返回展开二次贝塞尔曲线所需的线段数。均分为 n 段时，折线与曲线的最大偏差为
+// |p0 - 2p1 + p2| / (8n²)，据此取满足 flatness 的最小 n
+func curveSegments(p0, p1, p2 vec, flatness float64) int {
+	d := vec{p0.x - 2*p1.x + p2.x, p0.y - 2*p1.y + p2.y}.len()
+	if flatness <= 0 {
+		return maxCurveSegments
+	}
+	n := int(math.Ceil(math.Sqrt(d / (8 * flatness))))
+	return min(max(n, 1), maxCurveSegments)
+}
@@ -0,0 +1,20 @@
+package mapper
+
+import "testing"
+
+func TestFlattenContour(t *testing.T) {
+	// 两个相邻控制点之间会补上隐含的曲线上的点 (100, 50)
+	contour := toTruetypePoints([]testPoint{{0, 0, false}, {100, 0, true}, {100, 100, true}})
+
+	points := flattenContour(contour, 1)
+	if len(points) != 9 {
+		t.Fatalf("got %d points, want 9", len(points))
+	}
+	if p := points[4]; p.x != 100 || p.y != 50 {
+		t.Errorf("implied on-curve point = %v, want {100 50}", p)
+	}
+
+	if coarse := flattenContour(contour, 50); len(coarse) != 2 {
+		t.Errorf("coarse flatness produced %d points, want 2", len(coarse))
+	}
+}
@@ -0,0 +1,86 @@
+package mapper
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// FourierMatcher 返回按傅里叶描述子比较的 GlyphMatcher：每个轮廓展开为折线后沿弧长重新采样为 samples 个点，
+// 把点序列看作复数做离散傅里叶变换，保留 ±1~±coefficients 次谐波的幅值并除以一次谐波的幅值。
+// 描述子与轮廓的点数、点的分布和起点无关，特殊字体和标准字体用不同数量的点编码同一个形状时仍然可以匹配；
+// 同时也与平移、缩放和旋转无关，需要区分方向时可以与其他 matcher 组合使用。
+// 两个字形的轮廓数量必须相同，tolerance 是对应轮廓的描述子之间允许的最大欧氏距离，例如 0.05
+func FourierMatcher(samples, coefficients int, tolerance float64) GlyphMatcher {
+	samples = max(samples, 8)
+	coefficients = min(max(coefficients, 2), samples/2-1)
+	return GlyphMatcherFunc(func(special, standard GlyphData) (bool, float64) {
+		if len(special.Ends) != len(standard.Ends) {
+			return false, math.Inf(1)
+		}
+		a := fourierDescriptors(special, samples, coefficients)
+		b := fourierDescriptors(standard, samples, coefficients)
+		var distance float64
+		for i := range a {
+			var sum float64
+			for j := range a[i] {
+				sum += (a[i][j] - b[i][j]) * (a[i][j] - b[i][j])
+			}
+			distance = max(distance, math.Sqrt(sum))
+		}
+		if distance > tolerance {
+			return false, math.Inf(1)
+		}
+		return true, relativeDeviation(distance, 1, tolerance)
+	})
+}
+
+// fourierDescriptors 计算字形每个轮廓的傅里叶描述子。最后一个分量是轮廓的一次谐波幅值在整个字形中的占比，
+// 用于区分形状相同但大小比例不同的轮廓；退化为一个点的轮廓描述子全为 0
+func fourierDescriptors(glyph GlyphData, samples, coefficients int) [][]float64 {
+	bounds := glyphBounds(glyph, [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)})
+	flatness := max(bounds[2]-bounds[0], bounds[3]-bounds[1]) / 1000
+	descriptors := make([][]float64, 0, len(glyph.Ends))
+	var scales []float64
+	var total float64
+	start := 0
+	for _, end := range glyph.Ends {
+		poly := flattenContour(glyph.Points[start:end], flatness)
+		start = end
+		var perimeter float64
+		for i := range poly {
+			perimeter += poly[(i+1)%len(poly)].sub(poly[i]).len()
+		}
+		points := resampleContour(poly, perimeter, samples)
+
+		descriptor := make([]float64, 2*coefficients)
+		var scale float64
+		if len(points) == samples {
+			harmonic := func(k int) complex128 {
+				var sum complex128
+				for n, p := range points {
+					sum += complex(p.x, p.y) * cmplx.Exp(complex(0, -2*math.Pi*float64(k*n)/float64(samples)))
+				}
+				return sum
+			}
+			// 逆时针和顺时针的轮廓，一次谐波分别落在 +1 和 -1 上，取较大的一个作为基准
+			scale = max(cmplx.Abs(harmonic(1)), cmplx.Abs(harmonic(-1)))
+			if scale > 0 {
+				for k := 1; k <= coefficients; k++ {
+					descriptor[2*(k-1)] = cmplx.Abs(harmonic(k)) / scale
+					descriptor[2*(k-1)+1] = cmplx.Abs(harmonic(-k)) / scale
+				}
+			}
+		}
+		descriptors = append(descriptors, descriptor)
+		scales = append(scales, scale)
+		total += scale
+	}
+	for i := range descriptors {
+		share := 0.0
+		if total > 0 {
+			share = scales[i] / total
+		}
+		descriptors[i] = append(descriptors[i], share)
+	}
+	return descriptors
+}
@@ -0,0 +1,33 @@
+package mapper
+
+import "testing"
+
+func TestFourierMatcher(t *testing.T) {
+	// 特殊字形每条边上多了不同数量的中点，逐点比较要求点数相同，无法匹配
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{{{100, 100, false}, {100, 350, false}, {100, 600, false}, {350, 600, false}, {600, 600, false}, {600, 225, false}, {600, 100, false}}}, advance: 800},
+		{contours: [][]testPoint{{{0, 0, false}, {0, 300, false}, {0, 600, false}, {200, 600, false}, {200, 200, false}, {350, 200, false}, {500, 200, false}, {500, 0, false}}}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{lShape()}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'L': 2, 'O': 3})
+
+	plain, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := plain.Mapping(0xE000, 0xE001); len(got) != 0 {
+		t.Errorf("re-encoded glyphs matched with point comparison: %v", got)
+	}
+
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithMatchers(FourierMatcher(64, 8, 0.05)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := mapper.Mapping(0xE000, 0xE001)
+	if len(got) != 2 || got[0xE000] != 'O' || got[0xE001] != 'L' {
+		t.Errorf("got %v, want U+E000 => O, U+E001 => L", got)
+	}
+}
@@ -0,0 +1,88 @@
+package mapper
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// WithRawGlyphMatch 在逐个比较轮廓之前，先按 glyf 表中每个简单字形原始数据的哈希查找完全相同的标准字形。
+// 混淆字体只打乱了 cmap、沿用原来的 glyf 数据时，大部分字符都可以这样直接匹配，耗时从几分钟降到几毫秒。
+// 命中时不再比较其他候选，即使 FirstMatch 策略下排在前面的候选也在容差之内；CFF 字体和复合字形不受影响
+func WithRawGlyphMatch() Option {
+	return func(g *GlyphOutlineMapper) {
+		g.rawGlyphMatch = true
+	}
+}
+
+// rawGlyphSource 是能够读取字形原始数据的字体，目前只有 glyf 轮廓的字体支持
+type rawGlyphSource interface {
+	// RawGlyph 返回简单字形在 glyf 表中的原始数据。复合字形引用的子字形索引在两个字体中未必对应同一个字形，
+	// 空白字形没有数据，这两种情况都返回 nil
+	RawGlyph(index int) []byte
+}
+
+func (s truetypeSource) RawGlyph(index int) []byte {
+	if index < 0 || index >= s.numGlyphs {
+		return nil
+	}
+	var start, end int
+	if s.longLoca {
+		if len(s.loca) < 4*(index+2) {
+			return nil
+		}
+		start, end = int(binary.BigEndian.Uint32(s.loca[4*index:])), int(binary.BigEndian.Uint32(s.loca[4*index+4:]))
+	} else {
+		if len(s.loca) < 2*(index+2) {
+			return nil
+		}
+		start, end = 2*int(binary.BigEndian.Uint16(s.loca[2*index:])), 2*int(binary.BigEndian.Uint16(s.loca[2*index+2:]))
+	}
+	if start >= end || end > len(s.glyf) || end-start < 10 || int16(binary.BigEndian.Uint16(s.glyf[start:])) < 0 {
+		return nil
+	}
+	return s.glyf[start:end]
+}
+
+// glyfHash 是字形原始数据的 SHA-256，零值表示没有可用的原始数据
+type glyfHash [sha256.Size]byte
+
+// rawGlyphHash 返回字体 f 中字形 index 原始数据的哈希
+func rawGlyphHash(f glyphSource, index int) glyfHash {
+	raw, ok := f.(rawGlyphSource)
+	if !ok {
+		return glyfHash{}
+	}
+	data := raw.RawGlyph(index)
+	if data == nil {
+		return glyfHash{}
+	}
+	return sha256.Sum256(data)
+}
+
+// indexRawGlyph 记录标准字形原始数据的哈希，同样的数据只记录优先级最高的一个
+func (c *standardCache) indexRawGlyph(f glyphSource, glyph *cachedGlyph) {
+	glyph.glyfHash = rawGlyphHash(f, f.Index(glyph.r))
+	if glyph.glyfHash == (glyfHash{}) {
+		return
+	}
+	if _, ok := c.byGlyfHash[glyph.glyfHash]; !ok {
+		c.byGlyfHash[glyph.glyfHash] = glyph
+	}
+}
+
+// rawMatch 查找原始字形数据与特殊字形完全相同的标准字形。混淆字体只打乱了 cmap、沿用原来的 glyf 数据时，
+// 大部分字符都可以这样直接匹配，不需要逐个比较轮廓。index 是特殊字形的索引，r 是它对应的字符（按字形索引映射时为 0）。
+// 同码位的字符数据也相同时优先返回它，与完整的候选扫描一致
+func (c *standardCache) rawMatch(special glyphSource, r rune, index int) *cachedGlyph {
+	if len(c.byGlyfHash) == 0 {
+		return nil
+	}
+	hash := rawGlyphHash(special, index)
+	if hash == (glyfHash{}) {
+		return nil
+	}
+	if identity := c.byRune[r]; r != 0 && identity != nil && identity.glyfHash == hash {
+		return identity
+	}
+	return c.byGlyfHash[hash]
+}
@@ -0,0 +1,38 @@
+package mapper
+
+import "testing"
+
+func TestGlyphOutlineMapper_RawGlyphFastPath(t *testing.T) {
+	glyphs := []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+		{components: []testComponent{{glyph: 1, dx: 10}}, advance: 800},
+		{advance: 500},
+	}
+	// 特殊字体沿用标准字体的 glyf 数据，只打乱了 cmap
+	special := buildTestFont(1000, glyphs, map[rune]rune{0xE000: 2, 0xE001: 1, 0xE002: 3})
+	standard := buildTestFont(1000, glyphs, map[rune]rune{'A': 1, 'B': 2, 'C': 3, ' ': 4})
+
+	// 让逐点比较总是失败，只有原始数据完全相同的简单字形可以匹配
+	never := GlyphMatcherFunc(func(_, _ GlyphData) (bool, float64) { return false, 0 })
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithMatchers(never), WithRawGlyphMatch())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := mapper.Mapping(0xE000, 0xE002)
+	if len(got) != 2 || got[0xE000] != 'B' || got[0xE001] != 'A' {
+		t.Errorf("got %v, want U+E000 => B, U+E001 => A and no composite match", got)
+	}
+	if result, ok := mapper.MappingRuneResult(0xE000); ok {
+		t.Errorf("MappingDetailed-style lookup should scan candidates, got %+v", result)
+	}
+
+	f, err := parseFont(standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := f.source.(rawGlyphSource)
+	if raw.RawGlyph(1) == nil || raw.RawGlyph(3) != nil || raw.RawGlyph(4) != nil || raw.RawGlyph(100) != nil {
+		t.Error("RawGlyph should only return simple glyph data")
+	}
+}
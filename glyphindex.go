@@ -0,0 +1,60 @@
+package mapper
+
+import (
+	"context"
+	"sync"
+)
+
+// GlyphID 是字形在字体中的索引
+type GlyphID uint16
+
+// MappingByGlyphIndex 不经过 cmap，直接遍历特殊字体中的全部字形（索引 0 的 .notdef 和空字形除外），
+// 返回字形索引到标准字符的映射。适用于 cmap 被删除或打乱、文本直接以字形索引编码的场景（例如 PDF）。
+// 每个字形与 Mapping 中的字符一样经过全部开启的比较方式（水平度量、候选索引、变换和各种兜底），
+// 只是没有同码位的候选。加载失败和比较超时的字形会被跳过
+func (g *GlyphOutlineMapper) MappingByGlyphIndex() map[GlyphID]rune {
+	result, _ := g.MappingByGlyphIndexContext(context.Background())
+	return result
+}
+
+// MappingByGlyphIndexContext 与 MappingByGlyphIndex 相同，但 ctx 被取消或超时后不再开始新的比较，
+// 等待已经开始的比较结束后返回目前为止的部分结果以及 ctx.Err()。标准字体缓存建立失败时返回空的结果和错误
+func (g *GlyphOutlineMapper) MappingByGlyphIndexContext(ctx context.Context) (map[GlyphID]rune, error) {
+	result := map[GlyphID]rune{}
+	cache, err := g.standardGlyphs(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, max(g.concurrent, 1))
+loop:
+	for index := 1; index < g.specialFont.NumGlyphs(); index++ {
+		select {
+		case <-ctx.Done():
+			break loop
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			special, loadErr := g.loadCachedGlyphIndex(g.specialFont, "special", 0, index)
+			if loadErr != nil {
+				g.metrics.AddError()
+				return
+			}
+			if len(special.outline.points) == 0 {
+				return
+			}
+			if match, ok, _ := g.matchSpecial(ctx, cache, special, index, false); ok && ctx.Err() == nil {
+				mu.Lock()
+				result[GlyphID(index)] = match.Standard
+				mu.Unlock()
+			}
+		}(index)
+	}
+	wg.Wait()
+	return result, ctx.Err()
+}
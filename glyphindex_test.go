@@ -0,0 +1,64 @@
+package mapper
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestGlyphOutlineMapper_MappingByGlyphIndex(t *testing.T) {
+	// 特殊字体没有任何 cmap 编码
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+		{advance: 500},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := mapper.MappingByGlyphIndex()
+	want := map[GlyphID]rune{1: 'B', 3: 'A'}
+	if len(got) != len(want) || got[1] != want[1] || got[3] != want[3] {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestGlyphOutlineMapper_MappingByGlyphIndexPipeline(t *testing.T) {
+	var mirrored []testPoint
+	for _, p := range lShape() {
+		mirrored = append(mirrored, testPoint{500 - p.x, p.y, p.off})
+	}
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{mirrored}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 500},
+	}, map[rune]rune{})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{lShape()}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'L': 1, 'A': 2})
+
+	// 镜像的字形经过变换后匹配，步进宽度不同的正方形被水平度量排除
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithTransforms(MirrorHorizontal), WithAdvanceFilter(0.1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := mapper.MappingByGlyphIndexContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[1] != 'L' {
+		t.Errorf("got %v, want only glyph 1 => 'L'", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got, err := mapper.MappingByGlyphIndexContext(ctx); !errors.Is(err, context.Canceled) || len(got) != 0 {
+		t.Errorf("got %v, %v, want no results and context.Canceled", got, err)
+	}
+}
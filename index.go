@@ -0,0 +1,181 @@
+package mapper
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// standardIndexVersion 是 StandardIndex.Save 写出的格式版本
+const standardIndexVersion = 3
+
+// StandardIndex 是预先加载好的标准字体字形，可以保存到文件并在之后创建的 mapper 中复用，
+// 避免每次都重新加载整个标准字体。索引只对相同的标准字体和相同的加载、归一化配置有效。
+// 生成时开启了 WithOutlineHash 的索引还保存每个字形归一化之后的轮廓哈希
+type StandardIndex struct {
+	checksum         string
+	scale            fixed.Int26_6
+	hinting          font.Hinting
+	majorContours    int
+	floatCoordinates bool
+	normalization    outlineNormalization
+	glyphs           map[rune]*outline
+
+	hashStep fixed.Int26_6 // 计算 hashes 时的量化步长，没有哈希时为 0
+	hashes   map[rune]uint64
+}
+
+// standardIndexFile 是 StandardIndex 的 gob 格式
+type standardIndexFile struct {
+	Version          int
+	Checksum         string
+	Scale            fixed.Int26_6
+	Hinting          font.Hinting
+	MajorContours    int
+	FloatCoordinates bool
+	Normalization    outlineNormalization
+	Glyphs           map[rune]standardIndexGlyph
+	HashStep         fixed.Int26_6
+}
+
+type standardIndexGlyph struct {
+	Points      []truetype.Point
+	Ends        []int
+	OutlineHash uint64
+}
+
+// BuildStandardIndex 加载标准字体中的全部字形并生成索引。opts 中影响字形加载和归一化的选项（WithScale、WithHinting、
+// WithFloatCoordinates、WithTranslationInvariance、WithCurveFlattening 等，以及展开曲线时的 SetFlatness，
+// 可以用调用它的自定义 Option 传入）需要与使用索引的 mapper 一致，否则索引会被忽略。
+// opts 中有 WithOutlineHash 时还按 opts 的容差计算每个字形归一化之后的轮廓哈希，容差相同的 mapper 直接使用
+func BuildStandardIndex(fontData []byte, opts ...Option) (*StandardIndex, error) {
+	f, err := parseFont(fontData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrStandardFontParse, err)
+	}
+	g := newGlyphOutlineMapper(f.source, f.source, opts...)
+	index := g.newStandardIndex(f.checksum)
+
+	// 索引保存归一化之前的轮廓，使用索引的 mapper 加载时再按自己的配置归一化
+	raw := newGlyphOutlineMapper(f.source, f.source, opts...)
+	raw.standardIndex = nil // 总是从字体中加载
+	raw.translationInvariant, raw.scaleInvariant, raw.directionInvariant, raw.resampleCount, raw.curveFlattening = false, false, false, 0, false
+	raw.outlineHashing = false
+	cache, err := raw.buildStandardCache(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if g.outlineHashing {
+		index.hashStep, index.hashes = g.tolerance+1, map[rune]uint64{}
+	}
+	em := g.loadScale(f.source)
+	for _, glyph := range cache.glyphs {
+		index.glyphs[glyph.r] = glyph.outline
+		if index.hashes != nil {
+			index.hashes[glyph.r] = outlineHash(g.normalizeOutline(glyph.outline, em), index.hashStep)
+		}
+	}
+	return index, nil
+}
+
+// newStandardIndex 按 g 的加载和归一化配置创建一个空的索引
+func (g *GlyphOutlineMapper) newStandardIndex(checksum string) *StandardIndex {
+	return &StandardIndex{
+		checksum:         checksum,
+		scale:            g.scale,
+		hinting:          g.hinting,
+		majorContours:    g.majorContours,
+		floatCoordinates: g.floatCoordinates,
+		normalization:    g.outlineNormalization(),
+		glyphs:           map[rune]*outline{},
+	}
+}
+
+// Len 返回索引中的字形数量
+func (idx *StandardIndex) Len() int {
+	return len(idx.glyphs)
+}
+
+// Save 把索引以 gob 格式写入 w
+func (idx *StandardIndex) Save(w io.Writer) error {
+	file := standardIndexFile{
+		Version:          standardIndexVersion,
+		Checksum:         idx.checksum,
+		Scale:            idx.scale,
+		Hinting:          idx.hinting,
+		MajorContours:    idx.majorContours,
+		FloatCoordinates: idx.floatCoordinates,
+		Normalization:    idx.normalization,
+		Glyphs:           make(map[rune]standardIndexGlyph, len(idx.glyphs)),
+		HashStep:         idx.hashStep,
+	}
+	for r, o := range idx.glyphs {
+		file.Glyphs[r] = standardIndexGlyph{Points: o.points, Ends: o.ends, OutlineHash: idx.hashes[r]}
+	}
+	return gob.NewEncoder(w).Encode(file)
+}
+
+// LoadStandardIndex 读取 Save 写出的索引，不支持的版本会返回错误
+func LoadStandardIndex(r io.Reader) (*StandardIndex, error) {
+	var file standardIndexFile
+	if err := gob.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("decode standard index failed: %w", err)
+	}
+	if file.Version != standardIndexVersion {
+		return nil, fmt.Errorf("unsupported standard index version %d", file.Version)
+	}
+	idx := &StandardIndex{
+		checksum:         file.Checksum,
+		scale:            file.Scale,
+		hinting:          file.Hinting,
+		majorContours:    file.MajorContours,
+		floatCoordinates: file.FloatCoordinates,
+		normalization:    file.Normalization,
+		glyphs:           make(map[rune]*outline, len(file.Glyphs)),
+		hashStep:         file.HashStep,
+	}
+	if idx.hashStep > 0 {
+		idx.hashes = make(map[rune]uint64, len(file.Glyphs))
+	}
+	for r, glyph := range file.Glyphs {
+		idx.glyphs[r] = &outline{points: glyph.Points, ends: glyph.Ends}
+		if idx.hashes != nil {
+			idx.hashes[r] = glyph.OutlineHash
+		}
+	}
+	return idx, nil
+}
+
+// WithStandardIndex 使用预先生成的索引代替加载标准字体（名字为 "standard" 的那个）中的字形。
+// 索引与标准字体或加载、归一化配置不一致时被忽略，仍然从字体中加载
+func WithStandardIndex(idx *StandardIndex) Option {
+	return func(g *GlyphOutlineMapper) {
+		g.standardIndex = idx
+	}
+}
+
+// usableStandardIndex 返回与当前标准字体和加载、归一化配置一致的索引，没有时返回 nil
+func (g *GlyphOutlineMapper) usableStandardIndex() *StandardIndex {
+	idx := g.standardIndex
+	if idx == nil || idx.checksum == "" || idx.checksum != g.standardChecksum {
+		return nil
+	}
+	if idx.scale != g.scale || idx.hinting != g.hinting || idx.majorContours != g.majorContours || idx.floatCoordinates != g.floatCoordinates ||
+		idx.normalization != g.outlineNormalization() {
+		return nil
+	}
+	return idx
+}
+
+// outlineHashes 返回索引中按 g 的容差计算的轮廓哈希，不一致时返回 nil。idx 必须是 usableStandardIndex 返回的索引
+func (g *GlyphOutlineMapper) outlineHashes(idx *StandardIndex) map[rune]uint64 {
+	if !g.outlineHashing || idx.hashes == nil || idx.hashStep != g.tolerance+1 {
+		return nil
+	}
+	return idx.hashes
+}
@@ -0,0 +1,157 @@
+package mapper
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"golang.org/x/image/math/fixed"
+)
+
+func TestStandardIndex(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+
+	built, err := BuildStandardIndex(standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if built.Len() != 2 {
+		t.Fatalf("index has %d glyphs, want 2", built.Len())
+	}
+	var buf bytes.Buffer
+	if err := built.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	index, err := LoadStandardIndex(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithStandardIndex(index))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mapper.Mapping(0xE000, 0xE001); len(got) != 2 || got[0xE000] != 'A' || got[0xE001] != 'B' {
+		t.Errorf("got %v, want U+E000 => 'A', U+E001 => 'B'", got)
+	}
+
+	// 去掉索引中的 'B' 后 U+E001 找不到匹配，说明字形来自索引而不是字体
+	delete(index.glyphs, 'B')
+	mapper.resetCache()
+	if got := mapper.Mapping(0xE000, 0xE001); len(got) != 1 {
+		t.Errorf("got %v, want only U+E000 from the index", got)
+	}
+
+	// 加载配置不同时忽略索引
+	scaled, err := NewGlyphOutlineMapper(special, standard, WithStandardIndex(index), WithScale(fixed.I(2000)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := scaled.Mapping(0xE000, 0xE001); len(got) != 2 {
+		t.Errorf("got %v, want the index to be ignored", got)
+	}
+}
+
+func TestStandardIndex_OutlineHashes(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+		{contours: [][]testPoint{square(200, 200, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+
+	built, err := BuildStandardIndex(standard, WithOutlineHash(), WithTranslationInvariance())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := built.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	index, err := LoadStandardIndex(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index.hashes) != 2 || index.hashes['B'] != built.hashes['B'] || index.hashes['B'] == 0 {
+		t.Fatalf("hashes = %v, want the saved hashes of both glyphs", index.hashes)
+	}
+
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithStandardIndex(index), WithOutlineHash(), WithTranslationInvariance())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 平移之后的哈希与特殊字形一致
+	if _, r, ok := mapper.MappingRune(0xE000); !ok || r != 'B' {
+		t.Errorf("U+E000: got %q (ok=%v), want 'B'", r, ok)
+	}
+	index.hashes['A'] = 12345
+	mapper.resetCache()
+	cache, err := mapper.standardGlyphs(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cache.byRune['A'].outlineHash; got != 12345 {
+		t.Errorf("outline hash = %d, want the hash stored in the index", got)
+	}
+
+	// 容差或归一化方式不同时重新计算
+	for _, opts := range [][]Option{
+		{WithStandardIndex(index), WithOutlineHash(), WithTranslationInvariance(), WithTolerance(20)},
+		{WithStandardIndex(index), WithOutlineHash()},
+	} {
+		other, err := NewGlyphOutlineMapper(special, standard, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cache, err := other.standardGlyphs(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := cache.byRune['A'].outlineHash; got == 12345 || got == 0 {
+			t.Errorf("outline hash = %d, want it recomputed", got)
+		}
+	}
+}
+
+func TestStandardIndex_Normalization(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1})
+	coarse := Option(func(g *GlyphOutlineMapper) { g.SetFlatness(4) })
+
+	index, err := BuildStandardIndex(standard, WithCurveFlattening(), coarse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 去掉索引中的 'A' 后，使用索引的 mapper 找不到匹配
+	delete(index.glyphs, 'A')
+	for _, tc := range []struct {
+		name     string
+		opts     []Option
+		useIndex bool
+	}{
+		{"same normalization", []Option{WithCurveFlattening(), coarse}, true},
+		{"no flattening", nil, false},
+		{"different flatness", []Option{WithCurveFlattening()}, false},
+		{"translation", []Option{WithCurveFlattening(), coarse, WithTranslationInvariance()}, false},
+	} {
+		mapper, err := NewGlyphOutlineMapper(special, standard, append(tc.opts, WithStandardIndex(index))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := mapper.Mapping(0xE000, 0xE000); (len(got) == 0) != tc.useIndex {
+			t.Errorf("%s: got %v, want the index used = %v", tc.name, got, tc.useIndex)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package mapper
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// WithNearestCandidates 为标准字形建立特征向量（轮廓数量、点数、边界框的大小和宽高比、前三个 Hu 不变矩）的 KD 树，
+// 每个特殊字符只比较特征最接近的 k 个候选，而不是线性扫描整个候选范围。轮廓完全相同的字形特征也相同，
+// 一定在最近的候选之中；形状略有差异的字形可能因为 k 太小被漏掉，k 取几十到几百比较稳妥。小于等于 0 时关闭
+func WithNearestCandidates(k int) Option {
+	return func(g *GlyphOutlineMapper) {
+		g.nearestK = max(k, 0)
+	}
+}
+
+// featureDims 是 glyphFeatures 的维数
+const featureDims = 7
+
+// glyphFeatures 计算字形的特征向量，各维的量级大致相当，直接用欧氏距离比较
+func glyphFeatures(glyph *cachedGlyph) [featureDims]float64 {
+	o := glyph.outline
+	b := glyph.bounds
+	size, aspect := 0.0, 0.0
+	if w, h := float64(b[2]-b[0]), float64(b[3]-b[1]); w > 0 && h > 0 {
+		size, aspect = math.Log2(max(w, h)), math.Log2(w/h)
+	}
+	hu := huMoments(o.points, o.ends)
+	return [featureDims]float64{
+		float64(len(o.ends)),
+		math.Log2(float64(len(o.points)) + 1),
+		size,
+		aspect,
+		logMoment(hu[0]),
+		logMoment(hu[1]),
+		logMoment(hu[2]),
+	}
+}
+
+// kdTree 是按特征向量组织的标准字形下标，nodes 以中位数为根按层交替切分各维
+type kdTree struct {
+	points [][featureDims]float64
+	nodes  []int // 隐式平衡树：区间 [lo, hi) 的根在 (lo+hi)/2
+}
+
+func newKDTree(points [][featureDims]float64) *kdTree {
+	t := &kdTree{points: points, nodes: make([]int, len(points))}
+	for i := range t.nodes {
+		t.nodes[i] = i
+	}
+	t.build(0, len(t.nodes), 0)
+	return t
+}
+
+func (t *kdTree) build(lo, hi, axis int) {
+	if hi-lo <= 1 {
+		return
+	}
+	mid := (lo + hi) / 2
+	nodes := t.nodes[lo:hi]
+	slices.SortFunc(nodes, func(a, b int) int {
+		return cmp.Compare(t.points[a][axis], t.points[b][axis])
+	})
+	next := (axis + 1) % featureDims
+	t.build(lo, mid, next)
+	t.build(mid+1, hi, next)
+}
+
+// nearest 返回与 q 最接近的 k 个点的下标，按下标升序排列
+func (t *kdTree) nearest(q [featureDims]float64, k int) []int {
+	var best []kdNeighbor // 按距离升序，最多 k 个
+	var search func(lo, hi, axis int)
+	search = func(lo, hi, axis int) {
+		if lo >= hi {
+			return
+		}
+		mid := (lo + hi) / 2
+		index := t.nodes[mid]
+		if d := squaredDistance(q, t.points[index]); len(best) < k || d < best[len(best)-1].distance {
+			i, _ := slices.BinarySearchFunc(best, d, func(n kdNeighbor, d float64) int { return cmp.Compare(n.distance, d) })
+			best = slices.Insert(best, i, kdNeighbor{index, d})
+			if len(best) > k {
+				best = best[:k]
+			}
+		}
+		diff := q[axis] - t.points[index][axis]
+		near, far := [2]int{lo, mid}, [2]int{mid + 1, hi}
+		if diff > 0 {
+			near, far = far, near
+		}
+		next := (axis + 1) % featureDims
+		search(near[0], near[1], next)
+		if len(best) < k || diff*diff < best[len(best)-1].distance {
+			search(far[0], far[1], next)
+		}
+	}
+	search(0, len(t.nodes), 0)
+	indexes := make([]int, len(best))
+	for i, n := range best {
+		indexes[i] = n.index
+	}
+	slices.Sort(indexes)
+	return indexes
+}
+
+type kdNeighbor struct {
+	index    int
+	distance float64
+}
+
+func squaredDistance(a, b [featureDims]float64) float64 {
+	var d float64
+	for i := range a {
+		d += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return d
+}
+
+// indexFeatures 为缓存中的全部字形建立 KD 树
+func (c *standardCache) indexFeatures() {
+	points := make([][featureDims]float64, len(c.glyphs))
+	for i, glyph := range c.glyphs {
+		points[i] = glyph.features
+	}
+	c.featureTree = newKDTree(points)
+}
+
+// candidates 返回可能与 special 匹配的标准字形，顺序与 glyphs 相同。建立了 KD 树时只返回特征最接近的 k 个，
+// 否则见 hashCandidates
+func (c *standardCache) candidates(special *cachedGlyph, k int) []*cachedGlyph {
+	if c.featureTree == nil || k <= 0 {
+		return c.hashCandidates(special)
+	}
+	indexes := c.featureTree.nearest(special.features, k)
+	candidates := make([]*cachedGlyph, len(indexes))
+	for i, index := range indexes {
+		candidates[i] = c.glyphs[index]
+	}
+	return candidates
+}
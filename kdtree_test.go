@@ -0,0 +1,65 @@
+package mapper
+
+import (
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestKDTreeNearest(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	points := make([][featureDims]float64, 500)
+	for i := range points {
+		for j := range points[i] {
+			points[i][j] = rng.Float64() * 10
+		}
+	}
+	tree := newKDTree(points)
+	for range 20 {
+		var q [featureDims]float64
+		for j := range q {
+			q[j] = rng.Float64() * 10
+		}
+		// 与暴力搜索的结果比较
+		want := make([]int, len(points))
+		for i := range want {
+			want[i] = i
+		}
+		sort.Slice(want, func(a, b int) bool {
+			return squaredDistance(q, points[want[a]]) < squaredDistance(q, points[want[b]])
+		})
+		want = want[:7]
+		slices.Sort(want)
+		if got := tree.nearest(q, 7); !slices.Equal(got, want) {
+			t.Errorf("nearest(%v) = %v, want %v", q, got, want)
+		}
+	}
+}
+
+func TestWithNearestCandidates(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 0, 500)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 450)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2, 'C': 3, 'D': 4})
+
+	metrics := &countingMetrics{}
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithNearestCandidates(1), WithMetrics(metrics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := mapper.Mapping(0xE000, 0xE001)
+	if got[0xE000] != 'D' || got[0xE001] != 'C' {
+		t.Errorf("got %v, want U+E000 => D, U+E001 => C", got)
+	}
+	// 每个字符只比较最近的一个候选
+	if metrics.compared != 2 {
+		t.Errorf("compared %d candidates, want 2", metrics.compared)
+	}
+}
@@ -0,0 +1,97 @@
+package mapper
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/bits"
+	"slices"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// WithLogger 用 logger 输出 Debug 级别的结构化日志：每个参与比较的特殊字符、尝试过的候选数量，
+// 以及每个被拒绝的候选的原因，用于排查某个字符为什么没有映射。默认不输出日志。
+// 拒绝原因的日志量与候选数量成正比，只适合在排查少数字符时开启 Debug 级别
+func WithLogger(logger *slog.Logger) Option {
+	return func(g *GlyphOutlineMapper) {
+		g.logger = logger
+	}
+}
+
+// debugEnabled 判断是否需要输出 Debug 日志，避免在没有开启时计算日志内容
+func (g *GlyphOutlineMapper) debugEnabled() bool {
+	return g.logger != nil && g.logger.Enabled(context.Background(), slog.LevelDebug)
+}
+
+func runeAttr(key string, r rune) slog.Attr {
+	return slog.String(key, fmt.Sprintf("%U", r))
+}
+
+// matchStage 是 matchGlyphs 中做出判断的比较方式
+type matchStage int
+
+const (
+	stageOutline        matchStage = iota // 逐点比较，包括 WithFloatCoordinates 的浮点比较
+	stageThreshold                        // SetMatchThreshold 的得分
+	stageMatchers                         // WithMatcher 添加的比较器
+	stagePerceptualHash                   // WithPerceptualHash 的汉明距离
+	stageSignature                        // WithShapeSignature 的相似度
+)
+
+// rejectReason 说明 matchGlyphs 在 stage 中为什么认为两个字形不一致，deviation 是 matchGlyphs 返回的偏差，只在输出日志时调用
+func (g *GlyphOutlineMapper) rejectReason(special, standard *cachedGlyph, stage matchStage, deviation float64) string {
+	switch stage {
+	case stageThreshold:
+		return fmt.Sprintf("score %.3f below match threshold %.3f", similarityScore(deviation), g.matchThreshold)
+	case stageMatchers:
+		a, b := special.glyphData(), standard.glyphData()
+		for i, m := range g.matchers {
+			if matched, _ := m.Match(a, b); !matched {
+				return fmt.Sprintf("rejected by matcher %d (%T)", i, m)
+			}
+		}
+		return "rejected by matcher"
+	case stagePerceptualHash:
+		return fmt.Sprintf("perceptual hash distance %d above %d", bits.OnesCount64(special.hash^standard.hash), g.hashDistance)
+	case stageSignature:
+		return fmt.Sprintf("signature similarity %.3f below threshold %.3f", signatureSimilarity(special.signature, standard.signature), g.signatureThreshold)
+	}
+	a, b := special.outline, standard.outline
+	if len(a.ends) != len(b.ends) {
+		return fmt.Sprintf("contour count %d != %d", len(a.ends), len(b.ends))
+	}
+	if !slices.Equal(a.ends, b.ends) || len(a.points) != len(b.points) {
+		return fmt.Sprintf("contour ends %v != %v", a.ends, b.ends)
+	}
+	if !g.ignorePointFlags {
+		for i := range a.points {
+			if a.points[i].Flags&1 != b.points[i].Flags&1 {
+				return fmt.Sprintf("point %d on-curve flag %d != %d", i, a.points[i].Flags&1, b.points[i].Flags&1)
+			}
+		}
+	}
+	if g.floatCoordinates {
+		for i := range special.points {
+			p, q := special.points[i], standard.points[i]
+			if dx, dy := abs(p.x-q.x), abs(p.y-q.y); dx > g.floatTolerance || dy > g.floatTolerance {
+				return fmt.Sprintf("point %d off by (%.4f, %.4f) em, tolerance %.4f", i, dx, dy, g.floatTolerance)
+			}
+		}
+		return "within tolerance"
+	}
+	for i := range a.points {
+		dx, dy := abs(a.points[i].X-b.points[i].X), abs(a.points[i].Y-b.points[i].Y)
+		if dx > g.tolerance || dy > g.tolerance {
+			return fmt.Sprintf("point %d off by (%d, %d), tolerance %d", i, dx, dy, g.tolerance)
+		}
+	}
+	return "within tolerance"
+}
+
+func abs[T float64 | fixed.Int26_6](v T) T {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,77 @@
+package mapper
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWithLogger(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 400)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 500)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2, 'C': 3})
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, got, ok := mapper.MappingRune(0xE000); !ok || got != 'C' {
+		t.Fatalf("got %q (ok=%v), want 'C'", got, ok)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`msg="reject candidate" special=U+E000 standard=U+0041 font=standard reason="point 1 off by`,
+		`msg="reject candidate" special=U+E000 standard=U+0042 font=standard reason="contour ends [4] != [3]"`,
+		`msg="rune examined" special=U+E000 candidates=3 matched=true standard=U+0043 score=1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWithLogger_RejectReasons(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 0, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1})
+	reject := GlyphMatcherFunc(func(_, _ GlyphData) (bool, float64) { return false, 0 })
+
+	for _, tc := range []struct {
+		name      string
+		opts      []Option
+		threshold float64
+		reason    string
+	}{
+		{"threshold", nil, 0.5, `reason="score 0.000 below match threshold 0.500"`},
+		{"matcher", []Option{WithMatchers(reject)}, 0, `reason="rejected by matcher 0 (mapper.GlyphMatcherFunc)"`},
+		// 距离不小于 hashBands 时不建立哈希索引，候选不会被提前排除
+		{"perceptual hash", []Option{WithPerceptualHash(hashBands)}, 0, `reason="perceptual hash distance`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			mapper, err := NewGlyphOutlineMapper(special, standard, append(tc.opts, WithLogger(logger))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			mapper.SetMatchThreshold(tc.threshold)
+			if _, _, ok := mapper.MappingRune(0xE000); ok {
+				t.Fatal("U+E000 should not match")
+			}
+			if out := buf.String(); !strings.Contains(out, tc.reason) {
+				t.Errorf("log does not contain %q:\n%s", tc.reason, out)
+			}
+		})
+	}
+}
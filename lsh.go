@@ -0,0 +1,68 @@
+package mapper
+
+import (
+	"math/rand/v2"
+	"slices"
+)
+
+// WithHashLSH 为感知哈希建立局部敏感哈希（按位采样）索引：tables 个表各自随机取哈希中的 bits 位作为键，
+// 与特殊字形在任意一个表中键相同的标准字形才会被比较。WithPerceptualHash 的 maxDistance 不小于 4 时
+// 无法建立精确的分段索引，候选覆盖整个 CJK 各扩展区的九万多个字形时可以用它做亚线性的近似查找。
+// 表越多、每个表的位数越少，漏掉相近字形的概率越低，候选也越多；maxDistance 为 8 时 32 个表、
+// 每表 12 位大约能找回 99% 的匹配。没有开启 WithPerceptualHash 时不起作用
+func WithHashLSH(tables, bits int) Option {
+	return func(g *GlyphOutlineMapper) {
+		g.lshTables = max(tables, 0)
+		g.lshBits = min(max(bits, 1), 64)
+	}
+}
+
+// hashLSH 是感知哈希的按位采样索引，表的采样位置由固定的种子生成，同样的配置总是得到同样的结果
+type hashLSH struct {
+	positions [][]uint8          // 每个表采样的位
+	buckets   []map[uint64][]int // 每个表的键 => glyphs 中的下标
+}
+
+func newHashLSH(tables, bits int) *hashLSH {
+	rng := rand.New(rand.NewPCG(0x5eed, uint64(tables)<<8|uint64(bits)))
+	l := &hashLSH{positions: make([][]uint8, tables), buckets: make([]map[uint64][]int, tables)}
+	for i := range tables {
+		perm := rng.Perm(64)[:bits]
+		slices.Sort(perm)
+		for _, p := range perm {
+			l.positions[i] = append(l.positions[i], uint8(p))
+		}
+		l.buckets[i] = map[uint64][]int{}
+	}
+	return l
+}
+
+// key 取出哈希在第 table 个表中采样的位
+func (l *hashLSH) key(table int, hash uint64) uint64 {
+	var key uint64
+	for _, p := range l.positions[table] {
+		key = key<<1 | hash>>p&1
+	}
+	return key
+}
+
+// indexLSH 为缓存中的全部字形建立按位采样索引
+func (c *standardCache) indexLSH(tables, bits int) {
+	c.lsh = newHashLSH(tables, bits)
+	for i, glyph := range c.glyphs {
+		for table, bucket := range c.lsh.buckets {
+			key := c.lsh.key(table, glyph.hash)
+			bucket[key] = append(bucket[key], i)
+		}
+	}
+}
+
+// lshCandidates 返回至少在一个表中与 hash 键相同的字形下标，按升序排列
+func (l *hashLSH) lshCandidates(hash uint64) []int {
+	var indexes []int
+	for table, bucket := range l.buckets {
+		indexes = append(indexes, bucket[l.key(table, hash)]...)
+	}
+	slices.Sort(indexes)
+	return slices.Compact(indexes)
+}
@@ -0,0 +1,59 @@
+package mapper
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestHashLSH(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	cache := &standardCache{}
+	for range 2000 {
+		cache.glyphs = append(cache.glyphs, &cachedGlyph{hash: rng.Uint64()})
+	}
+	cache.indexLSH(32, 12)
+
+	found := 0
+	for i := range 200 {
+		// 翻转 8 位得到一个相近的哈希
+		hash := cache.glyphs[i].hash
+		for _, p := range rng.Perm(64)[:8] {
+			hash ^= 1 << p
+		}
+		candidates := cache.lsh.lshCandidates(hash)
+		if !slices.IsSorted(candidates) {
+			t.Fatal("candidates should be sorted")
+		}
+		if slices.Contains(candidates, i) {
+			found++
+		}
+		if len(candidates) > len(cache.glyphs)/4 {
+			t.Errorf("%d candidates out of %d, want far fewer", len(candidates), len(cache.glyphs))
+		}
+	}
+	if found < 190 {
+		t.Errorf("found %d of 200 near matches, want at least 190", found)
+	}
+}
+
+func TestWithHashLSH(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithPerceptualHash(4), WithHashLSH(32, 12))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, r, ok := mapper.MappingRune(0xE000); !ok || r != 'B' {
+		t.Errorf("got %q (ok=%v), want 'B'", r, ok)
+	}
+	if cache, err := mapper.standardGlyphs(context.Background()); err != nil || cache.lsh == nil || cache.hashIndex != nil {
+		t.Errorf("expected an LSH index and no exact band index (err=%v)", err)
+	}
+}
@@ -1,98 +1,315 @@
 package mapper
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"iter"
+	"log/slog"
+	"maps"
+	"math"
+	"slices"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/golang/freetype/truetype"
 	"golang.org/x/image/font"
 	"golang.org/x/image/math/fixed"
+	"golang.org/x/text/unicode/norm"
 )
 
 type GlyphOutlineMapper struct {
-	specialFont          *truetype.Font
-	standardFont         *truetype.Font
+	specialFont          glyphSource
+	standardFont         glyphSource
+	extraStandardFonts   []namedFont
 	standardFontLastRune rune
 	concurrent           int
-	wg                   *sync.WaitGroup
-	sem                  chan struct{}
+	tolerance            fixed.Int26_6
+	toleranceEm          float64 // SetTolerance 设置的 em 比例，小于 0 时 tolerance 是 WithTolerance 设置的绝对容差
+	scale                fixed.Int26_6
+	hinting              font.Hinting
+	candidateRanges      []RuneRange
+	shapeSignature       bool
+	signatureThreshold   float64
+	matchThreshold       float64
+	ignorePointFlags     bool
+	floatCoordinates     bool
+	floatTolerance       float64
+	strategy             MatchStrategy
+	deterministic        bool
+	nearestFallback      bool
+	runeDeadline         time.Duration
+	logger               *slog.Logger
+	metrics              Metrics
+	matchers             []GlyphMatcher
+	majorContours        int
+	translationInvariant bool
+	directionInvariant   bool
+	startPointInvariant  bool
+	contourPermutation   bool
+	scaleInvariant       bool
+	resampleCount        int
+	curveFlattening      bool
+	transforms           []GlyphTransform
+	perceptualHash       bool
+	hashDistance         int
+	lshTables            int
+	lshBits              int
+	rawGlyphMatch        bool
+	outlineHashing       bool
+	advanceFilter        float64
+	nearestK             int
+	alternatives         int
+	recognizer           GlyphRecognizer
+	ocrSize              int
+	outputNorm           *norm.Form
+	ambiguousMu          sync.Mutex
+	ambiguous            []rune
+	lastMu               sync.Mutex // 保护最近一次批量映射的 errs 和 unmapped
+	errs                 []*GlyphLoadError
+	unmapped             []UnmappedRune
+	flatness             float64
+	progress             func(done, total int)
+	onMatch              func(special, standard rune, score float64)
+	checkpointStore      CheckpointStore
+	checkpointInterval   int
+	specialChecksum      string
+	standardChecksum     string
+	ignoreRunes          map[rune]bool
+	ignoreRanges         []RuneRange
+	standardIndex        *StandardIndex
+	cacheMu              sync.Mutex // 在建立缓存的整个过程中持有
+	cache                *standardCache
+	builtCache           atomic.Pointer[standardCache] // 与 cache 相同，不需要等待 cacheMu 就可以读取
 }
 
-func NewGlyphOutlineMapper(specialFontData, standardFontData []byte) (*GlyphOutlineMapper, error) {
-	mapper := GlyphOutlineMapper{
-		concurrent: 10,
-		wg:         &sync.WaitGroup{},
-		sem:        make(chan struct{}, 10),
+func NewGlyphOutlineMapper(specialFontData, standardFontData []byte, opts ...Option) (*GlyphOutlineMapper, error) {
+	specialFont, err := parseFont(specialFontData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSpecialFontParse, err)
 	}
-
-	specialFont, err := truetype.Parse(specialFontData)
+	standardFont, err := parseFont(standardFontData)
 	if err != nil {
-		return nil, fmt.Errorf("parse special font failed: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrStandardFontParse, err)
 	}
-	mapper.specialFont = specialFont
+	if sameGlyphs(specialFont, standardFont) {
+		return nil, ErrIdenticalFonts
+	}
+	mapper := newGlyphOutlineMapper(specialFont.source, standardFont.source, opts...)
+	mapper.specialChecksum, mapper.standardChecksum = specialFont.checksum, standardFont.checksum
+	return mapper, nil
+}
 
-	standardFont, err := truetype.Parse(standardFontData)
-	if err != nil {
-		return nil, fmt.Errorf("parse standard font failed: %w", err)
+// newGlyphOutlineMapper 基于已经解析好的两个字体创建 mapper
+func newGlyphOutlineMapper(specialFont, standardFont glyphSource, opts ...Option) *GlyphOutlineMapper {
+	return newGlyphOutlineMapperWithLastRune(specialFont, standardFont, findLastRune(standardFont), opts...)
+}
+
+// newGlyphOutlineMapperWithLastRune 与 newGlyphOutlineMapper 相同，但使用已经找到的标准字体最后一个字符，
+// 避免重复扫描整个码位空间
+func newGlyphOutlineMapperWithLastRune(specialFont, standardFont glyphSource, lastRune rune, opts ...Option) *GlyphOutlineMapper {
+	mapper := GlyphOutlineMapper{
+		specialFont:  specialFont,
+		standardFont: standardFont,
+		concurrent:   10,
+		tolerance:    fixed.Int26_6(10),
+		toleranceEm:  -1,
+		scale:        fixed.I(1000),
+		hinting:      font.HintingNone,
+		flatness:     defaultFlatness,
+		metrics:      nopMetrics{},
 	}
-	mapper.standardFont = standardFont
-	mapper.standardFontLastRune = mapper.findLastRune(standardFont)
-	return &mapper, nil
+	mapper.standardFontLastRune = lastRune
+	mapper.candidateRanges = []RuneRange{{Start: 0, End: mapper.standardFontLastRune}}
+
+	for _, opt := range opts {
+		opt(&mapper)
+	}
+	return &mapper
 }
 
 func (g *GlyphOutlineMapper) SetConcurrent(concurrent int) {
 	g.concurrent = concurrent
-	g.sem = make(chan struct{}, concurrent)
+}
+
+// SetTolerance 以 em 的百分比设置逐点比较时允许的误差，例如 0.1 表示每个坐标最多相差 em 的 0.1%，
+// 与加载尺寸无关。默认的容差约为 0.0156%（1000 ppem 下 10 个 26.6 单位）。
+// 浮点坐标模式下同样生效，相当于 WithFloatCoordinates(emPercent / 100)
+func (g *GlyphOutlineMapper) SetTolerance(emPercent float64) {
+	g.toleranceEm = max(emPercent/100, 0)
+	g.floatTolerance = emPercent / 100
+	g.applyToleranceEm()
+	// 缓存的解码结果依赖容差
+	g.resetCache()
+}
+
+// applyToleranceEm 按当前的加载尺寸把 SetTolerance 设置的 em 比例换算为 26.6 容差，加载尺寸变化后需要重新调用
+func (g *GlyphOutlineMapper) applyToleranceEm() {
+	if g.toleranceEm >= 0 {
+		g.tolerance = fixed.Int26_6(math.Round(g.toleranceEm * float64(g.scale)))
+	}
+}
+
+// SetCompareScale 设置加载字形时 1 em 对应的像素数，默认为 1000，与 WithScale(fixed.I(ppem)) 相同。
+// 加载时坐标已经按各自字体的 unitsPerEm 归一化，unitsPerEm 不同的两个字体在同一尺寸下可以直接比较。
+// SetTolerance 设置的容差按新的尺寸重新换算，WithTolerance 设置的绝对容差保持不变
+func (g *GlyphOutlineMapper) SetCompareScale(ppem int) {
+	g.scale = fixed.I(ppem)
+	g.applyToleranceEm()
+	g.resetCache()
+}
+
+// SetHinting 设置加载字形时使用的 hinting 方式，与 WithHinting 相同，但可以在创建 mapper 之后切换，
+// 便于比较不同 hinting 下的匹配结果。有些混淆字体只有经过网格对齐后才与原字体一致。
+// CFF 轮廓的字体没有 hinting 指令，不受影响
+func (g *GlyphOutlineMapper) SetHinting(hinting font.Hinting) {
+	g.hinting = hinting
+	g.resetCache()
+}
+
+// SetCompareMajorContours 只比较每个字形中边界框面积最大的 n 个轮廓，忽略装饰性的小轮廓。
+// n 为 0 时比较全部轮廓
+func (g *GlyphOutlineMapper) SetCompareMajorContours(n int) {
+	g.majorContours = n
+	g.resetCache()
+}
+
+// SetCandidateRanges 设置在标准字体中查找候选字符的范围，默认是 [0, 标准字体的最后一个字符]。
+// 范围按给定的顺序搜索，FirstMatch 策略下排在前面的范围优先；Start 大于 End 的范围为空
+func (g *GlyphOutlineMapper) SetCandidateRanges(ranges []RuneRange) {
+	g.candidateRanges = slices.Clone(ranges)
+	g.resetCache()
+}
+
+// SetFlatness 设置展开曲线时折线与曲线之间允许的最大偏差，单位是 1000 单位 em 下的字体单位，
+// 与字体实际的 unitsPerEm 和加载尺寸无关。默认值为 1，值越大展开后的点越少、比较越快，值越小越精确
+func (g *GlyphOutlineMapper) SetFlatness(units float64) {
+	g.flatness = units
+	g.resetCache()
+}
+
+// flatnessFor 把 flatness 换算为字体 f 加载后的坐标单位
+func (g *GlyphOutlineMapper) flatnessFor(f glyphSource) float64 {
+	return g.flatness / 1000 * float64(g.loadScale(f))
 }
 
 func (g *GlyphOutlineMapper) GlyphOutlineEqual(specialUnicode, standardUnicode rune) bool {
+	equal, _ := g.glyphOutlineEqual(specialUnicode, standardUnicode)
+	return equal
+}
+
+// GlyphOutlineEqualE 与 GlyphOutlineEqual 相同，但字形加载失败时返回 *GlyphLoadError，
+// 而不是当作不一致处理
+func (g *GlyphOutlineMapper) GlyphOutlineEqualE(specialUnicode, standardUnicode rune) (bool, error) {
+	equal, err := g.glyphOutlineEqual(specialUnicode, standardUnicode)
+	if err != nil {
+		return false, err
+	}
+	return equal, nil
+}
+
+// GlyphOutlineEqualAt 以 ppem 的尺寸加载两个字形，并用 tol 作为逐点比较的容差，不影响 mapper 本身的配置。
+// 用于在更高的分辨率下复核个别临界的匹配。归一化和轮廓、起点的对应方式（WithStartPointInvariance、
+// WithContourPermutation 等）与 mapper 的逐点比较相同，WithFloatCoordinates 和其他比较方式不起作用
+func (g *GlyphOutlineMapper) GlyphOutlineEqualAt(specialUnicode, standardUnicode rune, ppem int, tol fixed.Int26_6) (bool, error) {
+	index1 := g.specialFont.Index(specialUnicode)
+	index2 := g.standardFont.Index(standardUnicode)
+	if index1 == 0 || index2 == 0 {
+		return false, nil
+	}
+
+	outline1, err := g.loadGlyph(g.specialFont, index1, fixed.I(ppem))
+	if err != nil {
+		return false, &GlyphLoadError{Font: "special", Rune: specialUnicode, Index: truetype.Index(index1), Err: err}
+	}
+	outline2, err := g.loadGlyph(g.standardFont, index2, fixed.I(ppem))
+	if err != nil {
+		return false, &GlyphLoadError{Font: "standard", Rune: standardUnicode, Index: truetype.Index(index2), Err: err}
+	}
+	glyph1, glyph2 := g.glyphAt(outline1, fixed.I(ppem)), g.glyphAt(outline2, fixed.I(ppem))
+	equal, _ := g.compareOutlinePipeline(glyph1, glyph2, tol)
+	return equal, nil
+}
+
+// glyphAt 按 mapper 的归一化方式处理以 em 尺寸加载的轮廓，只填写逐点比较需要的数据
+func (g *GlyphOutlineMapper) glyphAt(o *outline, em fixed.Int26_6) *cachedGlyph {
+	o = g.normalizeOutline(o, em)
+	return &cachedGlyph{outline: o, bounds: outlineBounds(o)}
+}
+
+// glyphOutlineEqual 与 GlyphOutlineEqual 相同，但会返回字形加载失败的原因
+func (g *GlyphOutlineMapper) glyphOutlineEqual(specialUnicode, standardUnicode rune) (bool, *GlyphLoadError) {
 	// 获取字符在字体中的索引
 	index1 := g.specialFont.Index(specialUnicode)
 	index2 := g.standardFont.Index(standardUnicode)
 
 	if index1 == 0 || index2 == 0 {
-		return false // 字符不存在
+		return false, nil // 字符不存在
 	}
 
 	// 获取字形轮廓数据
-	var buf1, buf2 truetype.GlyphBuf
-	err := buf1.Load(g.specialFont, fixed.I(1000), index1, font.HintingNone)
+	glyph1, err := g.loadCachedGlyph(g.specialFont, "special", specialUnicode)
 	if err != nil {
-		return false
+		return false, err
 	}
-	err = buf2.Load(g.standardFont, fixed.I(1000), index2, font.HintingNone)
+	glyph2, err := g.loadCachedGlyph(g.standardFont, "standard", standardUnicode)
 	if err != nil {
-		return false
+		return false, err
 	}
 
 	// 实际比较轮廓数据
-	return g.compareGlyphOutlines(&buf1, &buf2)
+	equal, _, _ := g.matchGlyphs(glyph1, glyph2)
+	return equal, nil
 }
 
-// compareGlyphOutlines 比较两个字形的轮廓数据
-func (g *GlyphOutlineMapper) compareGlyphOutlines(buf1, buf2 *truetype.GlyphBuf) bool {
+// loadGlyph 以 scale 加载字形轮廓并整理轮廓顺序
+func (g *GlyphOutlineMapper) loadGlyph(f glyphSource, index int, scale fixed.Int26_6) (*outline, error) {
+	o, err := f.Load(index, scale, g.hinting)
+	if err != nil {
+		return nil, err
+	}
+	canonicalizeContours(o)
+	keepMajorContours(o, g.majorContours)
+	return o, nil
+}
+
+// loadScale 返回加载字形时使用的缩放比例。浮点坐标模式下按字体自身的 unitsPerEm 加载，
+// 此时轮廓中的坐标就是字体里未经缩放和取整的原始坐标
+func (g *GlyphOutlineMapper) loadScale(f glyphSource) fixed.Int26_6 {
+	if g.floatCoordinates {
+		return fixed.Int26_6(f.UnitsPerEm())
+	}
+	return g.scale
+}
+
+// compareGlyphOutlines 比较两个字形的轮廓数据，同时返回相对于容差的平均偏差
+// （每个点取 x、y 偏差中较大的一个，再除以容差），0 表示完全一致。
+// 曲线上的点与控制点即使坐标相同也不匹配，ignoreFlags 为 true 时只比较坐标
+func compareGlyphOutlines(outline1, outline2 *outline, tolerance fixed.Int26_6, ignoreFlags bool) (bool, float64) {
 	// 1. 比较轮廓数量
-	if len(buf1.Ends) != len(buf2.Ends) {
-		return false
+	if len(outline1.ends) != len(outline2.ends) {
+		return false, math.Inf(1)
 	}
 
 	// 2. 比较每个轮廓的端点
-	for i := range buf1.Ends {
-		if buf1.Ends[i] != buf2.Ends[i] {
-			return false
+	for i := range outline1.ends {
+		if outline1.ends[i] != outline2.ends[i] {
+			return false, math.Inf(1)
 		}
 	}
 
 	// 3. 比较轮廓点的数量
-	if len(buf1.Points) != len(buf2.Points) {
-		return false
+	if len(outline1.points) != len(outline2.points) {
+		return false, math.Inf(1)
 	}
 
 	// 4. 比较每个轮廓点的坐标（允许小的浮点误差）
-	tolerance := fixed.Int26_6(10) // 允许的误差范围
-	for i := range buf1.Points {
-		dx := buf1.Points[i].X - buf2.Points[i].X
-		dy := buf1.Points[i].Y - buf2.Points[i].Y
+	var total int64
+	for i := range outline1.points {
+		dx := outline1.points[i].X - outline2.points[i].X
+		dy := outline1.points[i].Y - outline2.points[i].Y
 
 		if dx < 0 {
 			dx = -dx
@@ -102,14 +319,78 @@ func (g *GlyphOutlineMapper) compareGlyphOutlines(buf1, buf2 *truetype.GlyphBuf)
 		}
 
 		if dx > tolerance || dy > tolerance {
-			return false
+			return false, math.Inf(1)
+		}
+		if !ignoreFlags && outline1.points[i].Flags&1 != outline2.points[i].Flags&1 {
+			return false, math.Inf(1)
 		}
+		total += int64(max(dx, dy))
+	}
+
+	return true, relativeDeviation(float64(total), float64(len(outline1.points)), float64(tolerance))
+}
+
+// compareFloatOutlines 比较两组以 em 为单位的浮点坐标，轮廓结构必须完全一致。
+// 开启 WithStartPointInvariance 时每个轮廓可以循环移位，轮廓顺序不会重新对应
+func (g *GlyphOutlineMapper) compareFloatOutlines(a, b *cachedGlyph) (bool, float64) {
+	total, ok := g.floatDeviation(a, b, g.floatTolerance)
+	if !ok {
+		return false, math.Inf(1)
 	}
+	return true, relativeDeviation(total, float64(len(a.points)), g.floatTolerance)
+}
 
-	return true
+// floatDeviation 逐个轮廓比较两组浮点坐标，返回偏差之和以及是否每个点都在 tolerance 之内。
+// 开启 WithStartPointInvariance 时每个轮廓取偏差最小的循环移位
+func (g *GlyphOutlineMapper) floatDeviation(a, b *cachedGlyph, tolerance float64) (float64, bool) {
+	if !slices.Equal(a.outline.ends, b.outline.ends) || len(a.points) != len(b.points) {
+		return 0, false
+	}
+	var total float64
+	start := 0
+	for _, end := range a.outline.ends {
+		n := end - start
+		best, found := 0.0, false
+		for shift := range max(n, 1) {
+			if shift > 0 && !g.startPointInvariant {
+				break
+			}
+			sum, ok := 0.0, true
+			for i := range n {
+				j := start + (i+shift)%n
+				dx := math.Abs(a.points[start+i].x - b.points[j].x)
+				dy := math.Abs(a.points[start+i].y - b.points[j].y)
+				if dx > tolerance || dy > tolerance || !g.ignorePointFlags && a.outline.points[start+i].Flags&1 != b.outline.points[j].Flags&1 {
+					ok = false
+					break
+				}
+				sum += math.Max(dx, dy)
+			}
+			if ok && (!found || sum < best) {
+				best, found = sum, true
+			}
+		}
+		if !found {
+			return 0, false
+		}
+		total += best
+		start = end
+	}
+	return total, true
 }
 
-func (g *GlyphOutlineMapper) findLastRune(font *truetype.Font) rune {
+// relativeDeviation 把 n 个点的偏差总和换算为相对于容差的平均偏差
+func relativeDeviation(total, n, tolerance float64) float64 {
+	if n == 0 || total == 0 {
+		return 0
+	}
+	if tolerance == 0 {
+		return math.Inf(1)
+	}
+	return total / n / tolerance
+}
+
+func findLastRune(font glyphSource) rune {
 	if font == nil {
 		return 0
 	}
@@ -123,100 +404,511 @@ func (g *GlyphOutlineMapper) findLastRune(font *truetype.Font) rune {
 	return 0
 }
 
-func (g *GlyphOutlineMapper) Mapping(start, end rune) map[rune]rune {
+func (g *GlyphOutlineMapper) Mapping(start, end rune) Mapping {
+	resultsMap, _ := g.MappingWithErrors(start, end)
+	return resultsMap
+}
+
+// MappingWithErrors 与 Mapping 相同，但不会因为个别损坏的字形而中断，
+// 加载失败的字形会被记录在返回的错误列表中（同一个字形只记录一次）
+func (g *GlyphOutlineMapper) MappingWithErrors(start, end rune) (Mapping, []*GlyphLoadError) {
+	results, errs, _, _ := g.mappingDetailed(context.Background(), start, end, false, nil)
+	return resultsMap(results), errs
+}
+
+// Errors 返回最近一次完成的批量映射（Mapping、MappingContext、MappingAll 等）中加载失败的字形，
+// 与 MappingWithErrors 返回的错误列表相同。为空说明结果没有因为损坏的字形而缺失
+func (g *GlyphOutlineMapper) Errors() []*GlyphLoadError {
+	g.lastMu.Lock()
+	defer g.lastMu.Unlock()
+	return slices.Clone(g.errs)
+}
+
+// MappingContext 与 Mapping 相同，但 ctx 被取消或超时后不再开始新的比较，
+// 等待已经开始的比较结束后返回目前为止的部分结果以及 ctx.Err()
+func (g *GlyphOutlineMapper) MappingContext(ctx context.Context, start, end rune) (Mapping, error) {
+	results, _, _, err := g.mappingDetailed(ctx, start, end, false, nil)
+	return resultsMap(results), err
+}
+
+// SpecialRunes 返回特殊字体 cmap 中编码的全部字符（包括私有使用区和普通字符），按码位升序排列
+func (g *GlyphOutlineMapper) SpecialRunes() []rune {
+	return g.specialFont.Runes()
+}
+
+// MappingAll 与 Mapping 相同，但只映射 SpecialRunes 返回的字符，不需要猜测特殊字符的范围
+func (g *GlyphOutlineMapper) MappingAll() Mapping {
+	runes := g.SpecialRunes()
+	results, _, _, _ := g.mappingRunes(context.Background(), slices.Values(runes), len(runes), false, nil, nil)
+	return resultsMap(results)
+}
+
+// MappingForText 只映射 text 中出现过的字符，每个字符只比较一次。
+// 文本通常只用到几百个不同的字符，比映射整个私有使用区快得多
+func (g *GlyphOutlineMapper) MappingForText(text string) Mapping {
+	runes := []rune(text)
+	slices.Sort(runes)
+	runes = slices.Compact(runes)
+	results, _, _, _ := g.mappingRunes(context.Background(), slices.Values(runes), len(runes), false, nil, nil)
+	return resultsMap(results)
+}
+
+func resultsMap(results []MappingResult) Mapping {
+	m := make(Mapping, len(results))
+	for _, result := range results {
+		m[result.Special] = result.Standard
+	}
+	return m
+}
+
+// MappingStream 在后台映射 [start, end] 内的字符，每找到一个匹配就立即发送到返回的 channel，
+// 全部完成后关闭 channel。结果的顺序不固定，调用方需要读完 channel，否则后台的比较会一直阻塞
+func (g *GlyphOutlineMapper) MappingStream(start, end rune) <-chan MappingResult {
+	ch := make(chan MappingResult, g.concurrent)
+	go func() {
+		defer close(ch)
+		g.mappingDetailed(context.Background(), start, end, false, func(result MappingResult) {
+			ch <- result
+		})
+	}()
+	return ch
+}
+
+// mappingDetailed 并发映射 [start, end] 内的字符，见 mappingRunes。设置了 WithCheckpoint 时从保存的进度继续。
+// detectAmbiguity 为 true 时结果中有歧义的字符会记录下来，供 AmbiguousRunes 读取
+func (g *GlyphOutlineMapper) mappingDetailed(ctx context.Context, start, end rune, detectAmbiguity bool, found func(MappingResult)) ([]MappingResult, []*GlyphLoadError, MappingStats, error) {
+	cp := g.newCheckpointer(start, end)
+	var saved []MappingResult
+	if cp != nil {
+		// 之前找到的结果直接沿用
+		start = cp.cp.Next
+		saved = slices.Clone(cp.cp.Results)
+		if found != nil {
+			for _, result := range saved {
+				found(result)
+			}
+		}
+	}
+	runes := func(yield func(rune) bool) {
+		for r := start; r <= end; r++ {
+			if !yield(r) {
+				return
+			}
+		}
+	}
+	results, errs, stats, err := g.mappingRunes(ctx, runes, max(int(end-start)+1, 0), detectAmbiguity, found, cp)
+	if cp != nil {
+		results = append(saved, results...)
+		sort.Slice(results, func(i, j int) bool { return results[i].Special < results[j].Special })
+		if cpErr := cp.finish(); cpErr != nil {
+			err = errors.Join(err, cpErr)
+		}
+	}
+	if detectAmbiguity {
+		g.recordAmbiguous(results)
+	}
+	return results, errs, stats, err
+}
+
+// mappingRunes 并发映射 runes 中的 total 个字符，结果按特殊字符升序排列。
+// found 不为 nil 时每找到一个结果都会调用一次，开启 WithDeterministicOrder 时按 runes 的顺序调用。
+// ctx 被取消时返回已经完成的部分结果以及 ctx.Err()，取消时还没有比较完的字符不计入统计。
+// cp 不为 nil 时记录每个字符的完成情况
+func (g *GlyphOutlineMapper) mappingRunes(ctx context.Context, runes iter.Seq[rune], total int, detectAmbiguity bool, found func(MappingResult), cp *checkpointer) ([]MappingResult, []*GlyphLoadError, MappingStats, error) {
 	results := &sync.Map{}
-	for i := start; i <= end; i++ {
-		g.wg.Add(1)
-		g.sem <- struct{}{}
+	loadErrors := map[glyphKey]*GlyphLoadError{}
+	var errsMu, progressMu sync.Mutex
+	emit := func(result MappingResult) {
+		if found != nil {
+			found(result)
+		}
+		if g.onMatch != nil {
+			progressMu.Lock()
+			g.onMatch(result.Special, result.Standard, result.Score)
+			progressMu.Unlock()
+		}
+	}
+	var order *resultOrder
+	if g.deterministic {
+		order = newResultOrder(emit)
+	}
+	done := 0
+	var durations []time.Duration // 特殊字体中存在、在取消之前比较完的每个字符的比较耗时
+	var examined []MappingResult  // 这些字符中找到的匹配
+	var unmapped []UnmappedRune
+	began := time.Now()
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, max(g.concurrent, 1))
+loop:
+	for i := range runes {
+		select {
+		case <-ctx.Done():
+			break loop
+		case sem <- struct{}{}:
+		}
+		cp.dispatch(i)
+		order.dispatch(i)
+		wg.Add(1)
 		go func(i rune) {
-			defer g.wg.Done()
-			defer func() { <-g.sem }()
+			defer wg.Done()
+			defer func() { <-sem }()
 
-			specialRune, standardRune, ok := g.MappingRune(i)
+			compareStart := time.Now()
+			result, ok, errs, timedOut := g.mappingRune(ctx, i, detectAmbiguity)
+			// 取消之后的比较可能提前结束，这些字符不算完成，继续时需要重新比较，也不计入统计
+			completed := ctx.Err() == nil
+			if completed {
+				cp.complete(i, result, ok)
+			}
+			if completed && g.specialFont.Index(i) != 0 && !g.ignored(i) {
+				elapsed := time.Since(compareStart)
+				var miss UnmappedRune
+				if !ok {
+					miss = g.unmappedReason(i, errs, timedOut)
+				}
+				progressMu.Lock()
+				durations = append(durations, elapsed)
+				if ok {
+					examined = append(examined, result)
+				}
+				if miss.Reason != 0 {
+					unmapped = append(unmapped, miss)
+				}
+				progressMu.Unlock()
+			}
 			if ok {
-				results.Store(specialRune, standardRune)
+				results.Store(result.Special, result)
+			}
+			if order != nil {
+				order.complete(i, result, ok)
+			} else if ok {
+				emit(result)
+			}
+			if len(errs) > 0 {
+				errsMu.Lock()
+				for _, err := range errs {
+					// 多个字符共用同一个损坏的字形时，固定记录码位最小的一个
+					key := glyphKey{err.Font, err.Index}
+					if existing, ok := loadErrors[key]; !ok || err.Rune < existing.Rune {
+						loadErrors[key] = err
+					}
+				}
+				errsMu.Unlock()
+			}
+			if g.progress != nil {
+				progressMu.Lock()
+				done++
+				g.progress(done, total)
+				progressMu.Unlock()
 			}
 		}(i)
 	}
-	g.wg.Wait()
-	close(g.sem)
-	resultsMap := map[rune]rune{}
-	results.Range(func(key, value any) bool {
-		resultsMap[key.(rune)] = value.(rune)
+	wg.Wait()
+	var resultsList []MappingResult
+	results.Range(func(_, value any) bool {
+		resultsList = append(resultsList, value.(MappingResult))
 		return true
 	})
-	return resultsMap
+	sort.Slice(resultsList, func(i, j int) bool { return resultsList[i].Special < resultsList[j].Special })
+	errs := slices.Collect(maps.Values(loadErrors))
+	sort.Slice(errs, func(i, j int) bool {
+		if errs[i].Font != errs[j].Font {
+			return errs[i].Font > errs[j].Font
+		}
+		return errs[i].Index < errs[j].Index
+	})
+	sort.Slice(unmapped, func(i, j int) bool { return unmapped[i].Rune < unmapped[j].Rune })
+	g.lastMu.Lock()
+	g.errs = errs
+	g.unmapped = unmapped
+	g.lastMu.Unlock()
+	return resultsList, errs, newMappingStats(examined, errs, durations, time.Since(began)), ctx.Err()
 }
 
 func (g *GlyphOutlineMapper) MappingRune(unicode rune) (specialRune, standardRune rune, ok bool) {
-	if ok = g.hasGlyph(g.specialFont, unicode); !ok {
-		return
+	result, ok, _, _ := g.mappingRune(context.Background(), unicode, false)
+	return result.Special, result.Standard, ok
+}
+
+// MappingRuneE 与 MappingRune 相同，但用 *RuneError 说明没有结果的原因：特殊字符没有字形时包装 ErrNoGlyph，
+// 没有匹配的候选时包装 ErrNoMatch，特殊字形损坏时包装 *GlyphLoadError（可以用 errors.Is(err, ErrGlyphLoad) 判断），
+// 比较超过 WithDeadline 的时间限制时包装 ErrCompareTimeout
+func (g *GlyphOutlineMapper) MappingRuneE(unicode rune) (rune, error) {
+	if has, err := g.specialFont.Has(unicode); err != nil || !has {
+		if err == nil {
+			err = ErrNoGlyph
+		} else {
+			err = &GlyphLoadError{Font: "special", Rune: unicode, Index: truetype.Index(g.specialFont.Index(unicode)), Err: err}
+		}
+		return 0, &RuneError{Rune: unicode, Font: "special", Err: err}
 	}
-	if ok = g.hasGlyph(g.standardFont, unicode); ok {
-		if ok = g.GlyphOutlineEqual(unicode, unicode); ok {
-			specialRune = unicode
-			standardRune = unicode
-			return
+	result, ok, errs, timedOut := g.mappingRune(context.Background(), unicode, false)
+	if ok {
+		return result.Standard, nil
+	}
+	if timedOut {
+		return 0, &RuneError{Rune: unicode, Font: "special", Err: ErrCompareTimeout}
+	}
+	for _, loadErr := range errs {
+		if loadErr.Font == "special" {
+			return 0, &RuneError{Rune: unicode, Font: "special", Err: loadErr}
 		}
 	}
+	return 0, &RuneError{Rune: unicode, Font: "standard", Err: ErrNoMatch}
+}
 
-	for j := rune(0); j <= g.standardFontLastRune; j++ {
-		if ok = g.hasGlyph(g.standardFont, j); !ok {
-			continue
-		}
-		if ok = g.GlyphOutlineEqual(unicode, j); ok {
-			specialRune = unicode
-			standardRune = j
-			return
+// mappingRune 查找与特殊字符轮廓一致的标准字符，额外返回查找过程中遇到的字形加载错误，以及是否超过了 WithDeadline 的时间限制。
+// 特殊字体字形损坏时直接放弃该字符；标准字体中损坏的候选字形会被跳过。
+// ctx 只用于中断标准字体缓存的建立，detectAmbiguity 见 pickMatch
+func (g *GlyphOutlineMapper) mappingRune(ctx context.Context, unicode rune, detectAmbiguity bool) (result MappingResult, ok bool, errs []*GlyphLoadError, timedOut bool) {
+	if g.ignored(unicode) {
+		return
+	}
+	has, err := g.specialFont.Has(unicode)
+	if err != nil {
+		g.metrics.AddError()
+		errs = append(errs, &GlyphLoadError{Font: "special", Rune: unicode, Index: truetype.Index(g.specialFont.Index(unicode)), Err: err})
+		return
+	}
+	if !has {
+		return
+	}
+	special, loadErr := g.loadCachedGlyph(g.specialFont, "special", unicode)
+	if loadErr != nil {
+		g.metrics.AddError()
+		return result, false, append(errs, loadErr), false
+	}
+
+	// 同码位和哈希的快速路径不会比较其他候选，需要检测歧义或者列出候选时跳过
+	fast := !detectAmbiguity && g.alternatives == 0
+	if fast {
+		if result, ok = g.identityMatch(special); ok {
+			return result, ok, errs, false
 		}
 	}
-	return
+
+	cache, err := g.standardGlyphs(ctx)
+	if err != nil {
+		return
+	}
+	errs = append(errs, cache.errs...)
+	result, ok, timedOut = g.matchSpecial(ctx, cache, special, g.specialFont.Index(unicode), detectAmbiguity)
+	return result, ok, errs, timedOut
 }
 
-func (g *GlyphOutlineMapper) hasGlyph(font *truetype.Font, char rune) bool {
-	if font == nil {
-		return false
+// matchSpecial 在标准字体缓存中查找与已加载的特殊字形一致的标准字符，index 是特殊字形在字体中的索引，
+// 返回是否超过了 WithDeadline 的时间限制。按字形索引映射时 special.r 为 0，没有同码位的候选
+func (g *GlyphOutlineMapper) matchSpecial(ctx context.Context, cache *standardCache, special *cachedGlyph, index int, detectAmbiguity bool) (result MappingResult, ok bool, timedOut bool) {
+	unicode := special.r
+	fast := !detectAmbiguity && g.alternatives == 0
+	if g.rawGlyphMatch && fast {
+		if standard := cache.rawMatch(g.specialFont, unicode, index); standard != nil {
+			g.metrics.AddMatch()
+			return newMappingResult(special, standard, 0), true, false
+		}
+	}
+	if g.outlineHashing && fast {
+		if result, ok = g.hashedMatch(cache, special); ok {
+			g.metrics.AddMatch()
+			return result, ok, false
+		}
 	}
 
-	// 方法1：检查字体索引
-	index := font.Index(char)
-	if index == 0 && char != 0 {
-		return false
+	// 比较的时间限制从标准字体缓存建立之后才开始计算
+	began := time.Now()
+	compareCtx := ctx
+	if g.runeDeadline > 0 {
+		var cancel context.CancelFunc
+		compareCtx, cancel = context.WithTimeout(ctx, g.runeDeadline)
+		defer cancel()
 	}
 
-	// 方法2：检查字形边界和advance
-	face := truetype.NewFace(font, &truetype.Options{Size: 12})
-	defer face.Close()
+	// 先尝试同码位的字符，再遍历标准字体中的全部字符（建立了 KD 树或感知哈希索引时只遍历相近的字符）
+	var identity *cachedGlyph
+	if unicode != 0 {
+		identity = cache.byRune[unicode]
+	}
+	tried := 0
+	candidatesFor := func(special *cachedGlyph) iter.Seq[*cachedGlyph] {
+		glyphs := cache.candidates(special, g.nearestK)
+		return func(yield func(*cachedGlyph) bool) {
+			if identity != nil && g.hmetricsClose(special, identity) {
+				if tried++; !yield(identity) {
+					return
+				}
+			}
+			for i, standard := range glyphs {
+				if i%64 == 0 && compareCtx.Err() != nil {
+					return
+				}
+				if standard == identity || !g.hmetricsClose(special, standard) {
+					continue
+				}
+				if tried++; !yield(standard) {
+					return
+				}
+			}
+		}
+	}
+	candidates := candidatesFor(special)
+	result, ok = g.pickMatch(special, candidates, detectAmbiguity)
+	if !ok && len(g.transforms) > 0 {
+		result, ok = g.transformedMatch(special, index, candidatesFor, detectAmbiguity)
+	}
+	if !ok && g.nearestFallback {
+		result, ok = g.nearestMatch(special, candidates)
+	}
+	if !ok && g.recognizer != nil {
+		result, ok = g.recognizeMatch(special)
+	}
+	if ok && g.alternatives > 0 {
+		chosen := result.Standard
+		result.Alternatives = g.topCandidates(special, cache.candidates(special, g.nearestK), g.alternatives, func(standard *cachedGlyph) bool {
+			return standard.r == chosen || !g.hmetricsClose(special, standard)
+		})
+	}
+	g.metrics.AddCompared(tried)
+	g.metrics.ObserveCompareLatency(time.Since(began))
+	if g.debugEnabled() {
+		g.logger.Debug("rune examined", runeAttr("special", unicode), slog.Int("candidates", tried), slog.Bool("matched", ok),
+			runeAttr("standard", result.Standard), slog.Float64("score", result.Score))
+	}
+	if g.runeDeadline > 0 && ctx.Err() == nil && compareCtx.Err() != nil {
+		// 没有比较完全部候选，结果不可信
+		g.metrics.AddError()
+		return MappingResult{}, false, true
+	}
+	if ok {
+		g.metrics.AddMatch()
+	}
+	return result, ok, false
+}
 
-	bounds, advance, ok := face.GlyphBounds(char)
-	if !ok {
-		return false
+// identityMatch 在标准字体缓存建立之前单独比较同码位的标准字符。很多混淆字体只打乱了一部分字符，
+// 其余字符与标准字体一致，这样映射少量字符时不需要先加载整个标准字体。
+// 缓存已经建立时返回 false，由完整的候选扫描处理（同码位的字符同样排在最前面）
+func (g *GlyphOutlineMapper) identityMatch(special *cachedGlyph) (MappingResult, bool) {
+	r := special.r
+	if g.builtCache.Load() != nil || g.ignored(r) || !slices.ContainsFunc(g.candidateRanges, func(rr RuneRange) bool { return r >= rr.Start && r <= rr.End }) {
+		return MappingResult{}, false
 	}
+	for _, f := range g.standardFonts() {
+		if has, err := f.source.Has(r); err != nil || !has {
+			continue
+		}
+		// 只比较优先级最高的字体中的字形，与缓存中 byRune 的选择一致
+		standard, loadErr := g.loadCachedGlyph(f.source, f.name, r)
+		if loadErr != nil {
+			return MappingResult{}, false
+		}
+		g.metrics.AddCompared(1)
+		matched, deviation, _ := g.matchGlyphs(special, standard)
+		if matched && (g.strategy == FirstMatch || deviation <= negligibleDeviation) {
+			g.metrics.AddMatch()
+			return newMappingResult(special, standard, deviation), true
+		}
+		return MappingResult{}, false
+	}
+	return MappingResult{}, false
+}
 
-	// 方法3：检查是否有实际的可视字形
-	if bounds.Empty() && advance == 0 {
-		return false
+// nearestMatch 返回偏差最小的候选，即使它超出了容差，结果被标记为 LowConfidence。
+// 轮廓结构不同、无法计算偏差的候选不参与比较
+func (g *GlyphOutlineMapper) nearestMatch(special *cachedGlyph, candidates iter.Seq[*cachedGlyph]) (result MappingResult, ok bool) {
+	best := math.Inf(1)
+	for standard := range candidates {
+		if deviation := g.candidateDeviation(special, standard); deviation < best {
+			result, ok, best = newMappingResult(special, standard, deviation), true, deviation
+		}
 	}
+	result.LowConfidence = ok
+	return result, ok
+}
 
-	// 方法4：对于私有使用区域的特殊检查
-	if char >= 0xE000 && char <= 0xF8FF {
-		// 私有使用区域，即使bounds为空也可能有字形
-		if advance > 0 {
-			return true
+// pickMatch 按匹配策略从候选字形中选出与特殊字形匹配的一个。
+// detectAmbiguity 为 true 时，找到结果后会继续扫描直到遇到第二个匹配的候选，以便标记歧义
+func (g *GlyphOutlineMapper) pickMatch(special *cachedGlyph, candidates iter.Seq[*cachedGlyph], detectAmbiguity bool) (result MappingResult, ok bool) {
+	best := math.Inf(1)
+	matches := 0
+	debug := g.debugEnabled()
+	for standard := range candidates {
+		matched, deviation, stage := g.matchGlyphs(special, standard)
+		if !matched {
+			if debug {
+				g.logger.Debug("reject candidate", runeAttr("special", special.r), runeAttr("standard", standard.r),
+					slog.String("font", standard.font), slog.String("reason", g.rejectReason(special, standard, stage, deviation)))
+			}
+			continue
 		}
-		if !bounds.Empty() {
-			return true
+		matches++
+		if !ok || (g.strategy == BestMatch && deviation < best) {
+			result, ok, best = newMappingResult(special, standard, deviation), true, deviation
 		}
-		if index > 0 {
-			return true
+		if detectAmbiguity && matches < 2 {
+			continue
+		}
+		// BestMatch 遇到可以忽略的偏差时，后面的候选不可能更好
+		if g.strategy == FirstMatch || best <= negligibleDeviation {
+			break
 		}
-		return false
 	}
+	result.Ambiguous = matches > 1
+	return result, ok
+}
 
-	// 一般情况下，有索引就认为存在
-	if index > 0 {
-		return true
+// MappingRuneAmong 只在给定的候选字符中查找与特殊字符匹配的标准字符，按匹配策略返回第一个或最佳的一个。
+// 适用于已经通过上下文把答案缩小到少数几个字符的场景，不会扫描整个标准字体
+func (g *GlyphOutlineMapper) MappingRuneAmong(unicode rune, candidates []rune) (rune, bool, error) {
+	if g.specialFont.Index(unicode) == 0 {
+		return 0, false, nil
+	}
+	special, err := g.loadCachedGlyph(g.specialFont, "special", unicode)
+	if err != nil {
+		return 0, false, err
 	}
 
-	return false
+	cache := g.builtCache.Load()
+	standards := func(yield func(*cachedGlyph) bool) {
+		for _, r := range candidates {
+			var standard *cachedGlyph
+			if cache != nil {
+				standard = cache.byRune[r]
+			} else if i := slices.IndexFunc(g.standardFonts(), func(f namedFont) bool { return f.source.Index(r) != 0 }); i >= 0 {
+				// 加载失败的候选直接跳过
+				f := g.standardFonts()[i]
+				standard, _ = g.loadCachedGlyph(f.source, f.name, r)
+			}
+			if standard != nil && !yield(standard) {
+				return
+			}
+		}
+	}
+	result, ok := g.pickMatch(special, standards, false)
+	return result.Standard, ok, nil
+}
+
+// glyphKey 唯一标识某个字体中的一个字形
+type glyphKey struct {
+	font  string
+	index truetype.Index
+}
+
+// GlyphLoadError 记录加载单个字形失败时的上下文
+type GlyphLoadError struct {
+	Font  string         // 出错的字体，"special"、"standard" 或 AddStandardFont 时指定的名字
+	Rune  rune           // 出错字形对应的字符
+	Index truetype.Index // 出错字形在字体中的索引
+	Err   error
+}
+
+func (e *GlyphLoadError) Error() string {
+	return fmt.Sprintf("load %s glyph %U (index %d) failed: %v", e.Font, e.Rune, e.Index, e.Err)
+}
+
+func (e *GlyphLoadError) Unwrap() error {
+	return e.Err
+}
+
+func (e *GlyphLoadError) Is(target error) bool {
+	return target == ErrGlyphLoad
 }
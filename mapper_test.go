@@ -1,14 +1,23 @@
 package mapper
 
 import (
+	"context"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"os"
+	"sync"
 	"testing"
+
+	"golang.org/x/image/math/fixed"
 )
 
 func TestGlyphOutlineMapper_MappingRune(t *testing.T) {
 	readFontData, _ := os.ReadFile("read.ttf")
 	miLantingFontData, _ := os.ReadFile("MI LANTING.ttf")
+	if len(readFontData) == 0 || len(miLantingFontData) == 0 {
+		t.Skip("read.ttf / MI LANTING.ttf not available")
+	}
 	mapper, err := NewGlyphOutlineMapper(readFontData, miLantingFontData)
 	if err != nil {
 		t.Fatal(err)
@@ -20,3 +29,348 @@ func TestGlyphOutlineMapper_MappingRune(t *testing.T) {
 	}
 	fmt.Printf("specialRune: %s => standardRune: %s\n", string(specialRune), string(standardRune))
 }
+
+// truncatedGlyph 声明了一个轮廓，但数据在端点表之后就被截断了
+func truncatedGlyph() []byte {
+	b := binary.BigEndian.AppendUint16(nil, 1)
+	b = append(b, make([]byte, 8)...)
+	return binary.BigEndian.AppendUint16(b, 40)
+}
+
+func TestGlyphOutlineMapper_MappingWithErrors(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{raw: truncatedGlyph(), advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2, 0xE002: 3})
+	standard := buildTestFont(1000, []testGlyph{
+		{raw: truncatedGlyph(), advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2, 'C': 3})
+
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, errs := mapper.MappingWithErrors(0xE000, 0xE002)
+	want := map[rune]rune{0xE000: 'B', 0xE002: 'C'}
+	if len(results) != len(want) {
+		t.Fatalf("got %v, want %v", results, want)
+	}
+	for k, v := range want {
+		if results[k] != v {
+			t.Errorf("mapping %U = %U, want %U", k, results[k], v)
+		}
+	}
+
+	if len(errs) != 2 {
+		t.Fatalf("got %d load errors, want 2: %v", len(errs), errs)
+	}
+	if errs[0].Font != "standard" || errs[0].Rune != 'A' || errs[0].Index != 1 {
+		t.Errorf("unexpected standard load error: %v", errs[0])
+	}
+	if errs[1].Font != "special" || errs[1].Rune != 0xE001 || errs[1].Index != 2 {
+		t.Errorf("unexpected special load error: %v", errs[1])
+	}
+}
+
+func TestGlyphOutlineMapper_FloatCoordinates(t *testing.T) {
+	// 在 16384 unitsPerEm 下相差 1 个单位，按 1000 ppem 加载后只差不到 4/64 像素
+	special := buildTestFont(16384, []testGlyph{
+		{contours: [][]testPoint{square(1000, 1000, 8000)}, advance: 10000},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(16384, []testGlyph{
+		{contours: [][]testPoint{square(1000, 1000, 8001)}, advance: 10000},
+		{contours: [][]testPoint{square(1000, 1000, 8000)}, advance: 10000},
+	}, map[rune]rune{'A': 1, 'B': 2})
+
+	plain, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plain.GlyphOutlineEqual(0xE000, 'A') {
+		t.Fatal("expected the fixed-point comparison to conflate the two glyphs")
+	}
+
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithFloatCoordinates(1e-5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mapper.GlyphOutlineEqual(0xE000, 'A') {
+		t.Error("float comparison should distinguish a one-unit difference")
+	}
+	if _, standardRune, ok := mapper.MappingRune(0xE000); !ok || standardRune != 'B' {
+		t.Fatalf("got %q (ok=%v), want 'B'", standardRune, ok)
+	}
+}
+
+func TestGlyphOutlineMapper_UnitsPerEm(t *testing.T) {
+	// 同一个设计分别以 1000 和 2048 unitsPerEm 保存，逐点比较和浮点坐标比较都应当归一化到相同的 em
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(250, 0, 500)}, advance: 1000},
+		{contours: [][]testPoint{triangle(125, 0, 750)}, advance: 1000},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+	standard := buildTestFont(2048, []testGlyph{
+		{contours: [][]testPoint{triangle(256, 0, 1536)}, advance: 2048},
+		{contours: [][]testPoint{square(512, 0, 1024)}, advance: 2048},
+	}, map[rune]rune{'A': 1, 'B': 2})
+
+	for name, opts := range map[string][]Option{
+		"points": nil,
+		"float":  {WithFloatCoordinates(0.001)},
+	} {
+		mapper, err := NewGlyphOutlineMapper(special, standard, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := mapper.Mapping(0xE000, 0xE001)
+		if len(got) != 2 || got[0xE000] != 'B' || got[0xE001] != 'A' {
+			t.Errorf("%s: got %v, want U+E000 => B, U+E001 => A", name, got)
+		}
+	}
+}
+
+func TestGlyphOutlineMapper_PointFlags(t *testing.T) {
+	// 坐标完全相同，但第二个点一个在曲线上、一个是控制点
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{{{100, 100, false}, {300, 600, false}, {500, 100, false}}}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{{{100, 100, false}, {300, 600, true}, {500, 100, false}}}, advance: 800},
+	}, map[rune]rune{'A': 1})
+
+	strict, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strict.GlyphOutlineEqual(0xE000, 'A') {
+		t.Error("an on-curve point should not match an off-curve point")
+	}
+	loose, err := NewGlyphOutlineMapper(special, standard, WithIgnorePointFlags())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loose.GlyphOutlineEqual(0xE000, 'A') {
+		t.Error("WithIgnorePointFlags should compare coordinates only")
+	}
+	if !loose.Config().IgnorePointFlags {
+		t.Error("Config().IgnorePointFlags = false")
+	}
+}
+
+func TestNewGlyphOutlineMapper_Errors(t *testing.T) {
+	valid := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{square(0, 0, 500)}, advance: 500}}, map[rune]rune{'A': 1})
+	cff2 := encodeTestSfnt(map[string][]byte{"CFF2": make([]byte, 8)})
+	copy(cff2, "OTTO")
+	woff2 := append([]byte("wOF2"), make([]byte, 8)...)
+	noGlyf := encodeTestSfnt(map[string][]byte{"head": make([]byte, 54)})
+
+	tests := []struct {
+		name              string
+		special, standard []byte
+		want              []error
+	}{
+		{"special truncated", nil, valid, []error{ErrSpecialFontParse, ErrFontParse}},
+		{"standard CFF2", valid, cff2, []error{ErrStandardFontParse, ErrFontParse, ErrUnsupportedFormat}},
+		{"standard WOFF2", valid, woff2, []error{ErrStandardFontParse, ErrUnsupportedFormat}},
+		{"special without glyf", noGlyf, valid, []error{ErrSpecialFontParse, ErrNoGlyfTable}},
+		{"identical fonts", valid, encodeTestWOFF(valid), []error{ErrIdenticalFonts}},
+	}
+	for _, tt := range tests {
+		_, err := NewGlyphOutlineMapper(tt.special, tt.standard)
+		for _, want := range tt.want {
+			if !errors.Is(err, want) {
+				t.Errorf("%s: error %v is not %v", tt.name, err, want)
+			}
+		}
+	}
+}
+
+func TestGlyphOutlineMapper_GlyphOutlineEqualAt(t *testing.T) {
+	special := buildTestFont(16384, []testGlyph{
+		{contours: [][]testPoint{square(1000, 1000, 8000)}, advance: 10000},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(16384, []testGlyph{
+		{contours: [][]testPoint{square(1000, 1000, 8001)}, advance: 10000},
+	}, map[rune]rune{'A': 1})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !mapper.GlyphOutlineEqual(0xE000, 'A') {
+		t.Fatal("glyphs should match at the default scale")
+	}
+	// 在 4096 ppem 下 1 个字体单位相差 16/64 像素，超出容差
+	if equal, err := mapper.GlyphOutlineEqualAt(0xE000, 'A', 4096, 10); err != nil || equal {
+		t.Errorf("GlyphOutlineEqualAt(4096) = %v, %v; want false", equal, err)
+	}
+	if equal, err := mapper.GlyphOutlineEqualAt(0xE000, 'A', 4096, 20); err != nil || !equal {
+		t.Errorf("GlyphOutlineEqualAt(4096, tol 20) = %v, %v; want true", equal, err)
+	}
+	if config := mapper.Config(); config.CompareScale != fixed.I(1000) || config.Tolerance != 10 {
+		t.Errorf("GlyphOutlineEqualAt changed the mapper config: %+v", config)
+	}
+	// 起点不同的轮廓按 mapper 配置的方式对应
+	shifted := square(1000, 1000, 8000)
+	shifted = append(shifted[1:], shifted[0])
+	special = buildTestFont(16384, []testGlyph{{contours: [][]testPoint{shifted}, advance: 10000}}, map[rune]rune{0xE000: 1})
+	for _, tc := range []struct {
+		opts []Option
+		want bool
+	}{
+		{nil, false},
+		{[]Option{WithStartPointInvariance()}, true},
+	} {
+		mapper, err := NewGlyphOutlineMapper(special, standard, tc.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if equal, err := mapper.GlyphOutlineEqualAt(0xE000, 'A', 4096, 20); err != nil || equal != tc.want {
+			t.Errorf("GlyphOutlineEqualAt with %d options = %v, %v; want %v", len(tc.opts), equal, err, tc.want)
+		}
+	}
+}
+
+func TestGlyphOutlineMapper_MappingContext(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1})
+
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := mapper.MappingContext(context.Background(), 0xE000, 0xE0FF)
+	if err != nil || len(got) != 1 || got[0xE000] != 'A' {
+		t.Fatalf("got %v, %v; want {U+E000: 'A'}", got, err)
+	}
+
+	cancelled, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	got, err = cancelled.MappingContext(ctx, 0xE000, 0xE0FF)
+	if !errors.Is(err, context.Canceled) || len(got) != 0 {
+		t.Errorf("got %v, %v; want no results and context.Canceled", got, err)
+	}
+}
+
+func TestGlyphOutlineMapper_MappingStream(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[rune]MappingResult{}
+	for result := range mapper.MappingStream(0xE000, 0xE0FF) {
+		got[result.Special] = result
+	}
+	if len(got) != 2 || got[0xE000].Standard != 'B' || got[0xE001].Standard != 'A' {
+		t.Fatalf("got %v, want U+E000 => 'B' and U+E001 => 'A'", got)
+	}
+	if got[0xE000].Score != 1 {
+		t.Errorf("exact match score = %v, want 1", got[0xE000].Score)
+	}
+}
+
+func TestGlyphOutlineMapper_AddStandardFont(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1})
+	// 第二个标准字体同样有正方形，但优先级较低
+	extra := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{'B': 1, 0x4E00: 2})
+
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mapper.AddStandardFont(extra, "standard"); err == nil {
+		t.Error("duplicate font name should be rejected")
+	}
+	if err := mapper.AddStandardFont(extra, "extra"); err != nil {
+		t.Fatal(err)
+	}
+	if end := mapper.Config().CandidateRanges[0].End; end != 0x4E00 {
+		t.Errorf("candidate range ends at %U, want U+4E00", end)
+	}
+
+	got := mapper.MappingDetailed(0xE000, 0xE001)
+	if len(got) != 2 {
+		t.Fatalf("got %v, want two results", got)
+	}
+	if got[0].Standard != 'A' || got[0].StandardFont != "standard" {
+		t.Errorf("U+E000 => %q from %q, want 'A' from the primary font", got[0].Standard, got[0].StandardFont)
+	}
+	if got[1].Standard != 0x4E00 || got[1].StandardFont != "extra" {
+		t.Errorf("U+E001 => %q from %q, want U+4E00 from the extra font", got[1].Standard, got[1].StandardFont)
+	}
+}
+
+func TestGlyphOutlineMapper_MappingRepeated(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1})
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithConcurrency(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 2; j++ {
+				if got := mapper.Mapping(0xE000, 0xE00F); len(got) != 1 || got[0xE000] != 'A' {
+					t.Errorf("got %v, want {U+E000: 'A'}", got)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestGlyphOutlineMapper_MappingForText(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := mapper.MappingForText("x\ue000y\ue000")
+	if len(got) != 1 || got[0xE000] != 'A' {
+		t.Errorf("got %v, want only U+E000 => 'A'", got)
+	}
+}
@@ -0,0 +1,209 @@
+package mapper
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Mapping 是特殊字符 => 标准字符的映射
+type Mapping map[rune]rune
+
+// Apply 把 s 中的特殊字符替换为对应的标准字符，没有映射的字符保持不变。
+// 结果不做 Unicode 规范化，需要时使用 ApplyNormalized 或 GlyphOutlineMapper.ApplyMapping
+func (m Mapping) Apply(s string) string {
+	return strings.Map(func(r rune) rune {
+		if standard, ok := m[r]; ok {
+			return standard
+		}
+		return r
+	}, s)
+}
+
+// ApplyNormalized 与 Apply 相同，但对替换后的文本按 form 做 Unicode 规范化
+func (m Mapping) ApplyNormalized(s string, form norm.Form) string {
+	return form.String(m.Apply(s))
+}
+
+// Coverage 返回 specials 中有映射的字符所占的比例，specials 通常是 GlyphOutlineMapper.SpecialRunes 的结果。
+// specials 为空时返回 1
+func (m Mapping) Coverage(specials []rune) float64 {
+	if len(specials) == 0 {
+		return 1
+	}
+	return 1 - float64(len(m.Unmapped(specials)))/float64(len(specials))
+}
+
+// Unmapped 返回 specials 中没有映射的字符，按码位升序排列且不重复
+func (m Mapping) Unmapped(specials []rune) []rune {
+	var unmapped []rune
+	for _, r := range specials {
+		if _, ok := m[r]; !ok {
+			unmapped = append(unmapped, r)
+		}
+	}
+	slices.Sort(unmapped)
+	return slices.Compact(unmapped)
+}
+
+// Chain 把 m 与 next 连接起来：m 把 x 映射到 y、next 把 y 映射到 z 时，结果把 x 映射到 z。
+// 用于 a => b 的混淆字体映射与 b => 标准字符的映射组合，next 中没有记录的字符不出现在结果中
+func (m Mapping) Chain(next Mapping) Mapping {
+	chained := make(Mapping, len(m))
+	for x, y := range m {
+		if z, ok := next[y]; ok {
+			chained[x] = z
+		}
+	}
+	return chained
+}
+
+// Normalize 返回把每个标准字符按 form（通常是 norm.NFKC）规范化后的新映射，例如全角数字变为 ASCII 数字、
+// CJK 兼容汉字变为统一汉字。规范化后不止一个字符的（例如 "㎏" => "kg"）保持原样，
+// 需要完整的规范化结果时对解码后的文本使用 WithOutputNormalization
+func (m Mapping) Normalize(form norm.Form) Mapping {
+	normalized := make(Mapping, len(m))
+	for special, standard := range m {
+		normalized[special] = standard
+		if s := form.String(string(standard)); utf8.RuneCountInString(s) == 1 {
+			normalized[special], _ = utf8.DecodeRuneInString(s)
+		}
+	}
+	return normalized
+}
+
+// ConflictPolicy 决定合并两个映射时，同一个特殊字符对应不同标准字符的情况如何处理
+type ConflictPolicy int
+
+const (
+	// KeepExisting 保留接收者中的记录
+	KeepExisting ConflictPolicy = iota
+	// PreferOther 使用参数中的记录
+	PreferOther
+	// DropConflicts 两边都不采用，结果中不包含这个特殊字符
+	DropConflicts
+)
+
+func (p ConflictPolicy) String() string {
+	switch p {
+	case KeepExisting:
+		return "keep-existing"
+	case PreferOther:
+		return "prefer-other"
+	case DropConflicts:
+		return "drop-conflicts"
+	}
+	return "unknown"
+}
+
+// Merge 返回合并 m 和 other 后的新映射，只出现在一边的记录都会保留，冲突的记录按 policy 处理。
+// m 和 other 都不会被修改
+func (m Mapping) Merge(other Mapping, policy ConflictPolicy) Mapping {
+	merged := make(Mapping, max(len(m), len(other)))
+	for special, standard := range m {
+		merged[special] = standard
+	}
+	for special, standard := range other {
+		existing, ok := merged[special]
+		switch {
+		case !ok || existing == standard:
+			merged[special] = standard
+		case policy == PreferOther:
+			merged[special] = standard
+		case policy == DropConflicts:
+			delete(merged, special)
+		}
+	}
+	return merged
+}
+
+// Diff 比较 m 和 other，m 作为旧映射，other 作为新映射，见 DiffMappings
+func (m Mapping) Diff(other Mapping) MappingDiff {
+	return DiffMappings(m, other)
+}
+
+// Reverse 见 ReverseMapping
+func (m Mapping) Reverse() Mapping {
+	return ReverseMapping(m)
+}
+
+// ReverseMapping 把特殊字符 => 标准字符的映射反转为标准字符 => 特殊字符，用于把普通文本重新编码为
+// 特殊字体下的文本。多个特殊字符对应同一个标准字符时取码位最小的一个，保证结果稳定
+func ReverseMapping(m map[rune]rune) map[rune]rune {
+	reversed := make(map[rune]rune, len(m))
+	for special, standard := range m {
+		if existing, ok := reversed[standard]; !ok || special < existing {
+			reversed[standard] = special
+		}
+	}
+	return reversed
+}
+
+// Collision 是映射到同一个标准字符的多个特殊字符
+type Collision struct {
+	Standard rune
+	Specials []rune // 按码位升序排列
+}
+
+// Ambiguities 返回多个特殊字符映射到同一个标准字符的情况，按标准字符升序排列。
+// 混淆字体通常是一一对应的，这样的冲突往往说明容差过宽，解码出的文本可能有错字。
+// 一个特殊字符同时匹配多个标准字符的情况需要用 MappingDetailed 检测，见 MappingResult.Ambiguous
+func (m Mapping) Ambiguities() []Collision {
+	byStandard := map[rune][]rune{}
+	for special, standard := range m {
+		byStandard[standard] = append(byStandard[standard], special)
+	}
+	var collisions []Collision
+	for standard, specials := range byStandard {
+		if len(specials) > 1 {
+			slices.Sort(specials)
+			collisions = append(collisions, Collision{Standard: standard, Specials: specials})
+		}
+	}
+	slices.SortFunc(collisions, func(a, b Collision) int { return int(a.Standard - b.Standard) })
+	return collisions
+}
+
+// Conflict 是破坏一一对应的一组字符，见 CheckBijective
+type Conflict = Collision
+
+// CheckBijective 检查 m 是否在两个方向上都是一一对应的，这是替换式混淆字体应有的性质，返回全部冲突，
+// 为空说明 m 可以无损地用 Reverse 反转。Mapping 的每个特殊字符只有一个结果，正方向总是成立，
+// 所以冲突都是多个特殊字符映射到同一个标准字符，与 Ambiguities 相同。冲突通常说明容差过宽
+func (m Mapping) CheckBijective() []Conflict {
+	return m.Ambiguities()
+}
+
+// Transformer 返回按 m 替换字符的 transform.Transformer，例如
+// transform.NewReader(resp.Body, m.Transformer())。无效的 UTF-8 字节原样输出
+func (m Mapping) Transformer() transform.Transformer {
+	return mappingTransformer{m: m}
+}
+
+type mappingTransformer struct {
+	transform.NopResetter
+	m Mapping
+}
+
+func (t mappingTransformer) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		rest := src[nSrc:]
+		if !atEOF && !utf8.FullRune(rest) {
+			return nDst, nSrc, transform.ErrShortSrc
+		}
+		r, size := utf8.DecodeRune(rest)
+		out := rest[:size]
+		if standard, ok := t.m[r]; ok && !(r == utf8.RuneError && size == 1) {
+			out = utf8.AppendRune(nil, standard)
+		}
+		if nDst+len(out) > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += copy(dst[nDst:], out)
+		nSrc += size
+	}
+	return nDst, nSrc, nil
+}
@@ -0,0 +1,127 @@
+package mapper
+
+import (
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestReverseMapping(t *testing.T) {
+	got := ReverseMapping(map[rune]rune{0xE001: 'A', 0xE000: 'A', 0xE002: 'B'})
+	want := map[rune]rune{'A': 0xE000, 'B': 0xE002}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReverseMapping = %v, want %v", got, want)
+	}
+}
+
+func TestMapping_Merge(t *testing.T) {
+	a := Mapping{0xE000: 'A', 0xE001: 'B'}
+	b := Mapping{0xE001: 'C', 0xE002: 'D'}
+
+	tests := []struct {
+		policy ConflictPolicy
+		want   Mapping
+	}{
+		{KeepExisting, Mapping{0xE000: 'A', 0xE001: 'B', 0xE002: 'D'}},
+		{PreferOther, Mapping{0xE000: 'A', 0xE001: 'C', 0xE002: 'D'}},
+		{DropConflicts, Mapping{0xE000: 'A', 0xE002: 'D'}},
+	}
+	for _, tt := range tests {
+		if got := a.Merge(b, tt.policy); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: got %v, want %v", tt.policy, got, tt.want)
+		}
+	}
+	if len(a) != 2 || a[0xE001] != 'B' {
+		t.Errorf("Merge modified the receiver: %v", a)
+	}
+
+	diff := a.Diff(b)
+	if len(diff.Added) != 1 || len(diff.Removed) != 1 || len(diff.Changed) != 1 {
+		t.Errorf("unexpected diff: %+v", diff)
+	}
+}
+
+func TestMapping_Apply(t *testing.T) {
+	m := Mapping{0xE000: '你', 0xE001: '好'}
+	if got, want := m.Apply("\ue000\ue001, \ue002"), "你好, \ue002"; got != want {
+		t.Errorf("Apply = %q, want %q", got, want)
+	}
+	specials := []rune{0xE002, 0xE000, 0xE001, 0xE003}
+	if got := m.Coverage(specials); got != 0.5 {
+		t.Errorf("Coverage = %v, want 0.5", got)
+	}
+	if got, want := m.Unmapped(specials), []rune{0xE002, 0xE003}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmapped = %U, want %U", got, want)
+	}
+}
+
+func TestMapping_Normalize(t *testing.T) {
+	m := Mapping{0xE000: '\uff11', 0xE001: '\uf900', 0xE002: '\u338f', 0xE003: 'A'}
+	got := m.Normalize(norm.NFKC)
+	want := Mapping{0xE000: '1', 0xE001: '\u8c48', 0xE002: '\u338f', 0xE003: 'A'}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for special, standard := range want {
+		if got[special] != standard {
+			t.Errorf("%U => %U, want %U", special, got[special], standard)
+		}
+	}
+	if m[0xE000] != '\uff11' {
+		t.Error("Normalize modified the receiver")
+	}
+}
+
+func TestMapping_Ambiguities(t *testing.T) {
+	m := Mapping{0xE000: 'A', 0xE001: 'B', 0xE002: 'A', 0xE003: 'C', 0xE004: 'C', 0xE005: 'A'}
+	want := []Collision{
+		{Standard: 'A', Specials: []rune{0xE000, 0xE002, 0xE005}},
+		{Standard: 'C', Specials: []rune{0xE003, 0xE004}},
+	}
+	if got := m.Ambiguities(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := (Mapping{0xE000: 'A'}).Ambiguities(); len(got) != 0 {
+		t.Errorf("got %v for a one-to-one mapping", got)
+	}
+}
+
+func TestMapping_CheckBijective(t *testing.T) {
+	m := Mapping{0xE000: 'A', 0xE001: 'B', 0xE002: 'A'}
+	conflicts := m.CheckBijective()
+	if len(conflicts) != 1 || conflicts[0].Standard != 'A' || !slices.Equal(conflicts[0].Specials, []rune{0xE000, 0xE002}) {
+		t.Errorf("got %v, want one conflict on A", conflicts)
+	}
+	delete(m, 0xE002)
+	if conflicts := m.CheckBijective(); len(conflicts) != 0 {
+		t.Errorf("got %v for a bijective mapping", conflicts)
+	}
+	if !reflect.DeepEqual(m.Reverse().Reverse(), m) {
+		t.Errorf("bijective mapping does not survive a round trip: %v", m.Reverse().Reverse())
+	}
+}
+
+func TestMapping_Transformer(t *testing.T) {
+	m := Mapping{0xE000: '你', 0xE001: 'A'}
+	input := "x\ue000\ue001\xff\ue000"
+	got, err := io.ReadAll(transform.NewReader(iotest.OneByteReader(strings.NewReader(input)), m.Transformer()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "x你A\xff你"; string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// dst 只够放下一个字符时分多次输出
+	dst := make([]byte, 3)
+	nDst, nSrc, err := m.Transformer().Transform(dst, []byte("\ue000\ue000"), true)
+	if err != transform.ErrShortDst || nDst != 3 || nSrc != 3 {
+		t.Errorf("Transform = %d, %d, %v; want 3, 3, ErrShortDst", nDst, nSrc, err)
+	}
+}
@@ -0,0 +1,132 @@
+package mapper
+
+import (
+	"math"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/math/fixed"
+)
+
+// GlyphData 是交给 GlyphMatcher 比较的一个已加载字形，坐标是按比较尺寸加载后的 26.6 定点数，y 轴向上
+type GlyphData struct {
+	Rune   rune             // 字形对应的字符
+	Font   string           // 字形所在字体的名字，特殊字体为 "special"
+	Points []truetype.Point // 轮廓点，Flags 最低位为 1 表示点在曲线上
+	Ends   []int            // 每个轮廓最后一个点之后的下标
+}
+
+// GlyphMatcher 判断两个字形是否一致，同时返回相对于容差的偏差：0 表示完全一致，
+// 1 表示恰好在容差边缘，不匹配时可以返回 +Inf。实现需要可以并发调用
+type GlyphMatcher interface {
+	Match(special, standard GlyphData) (bool, float64)
+}
+
+// GlyphMatcherFunc 让普通函数实现 GlyphMatcher
+type GlyphMatcherFunc func(special, standard GlyphData) (bool, float64)
+
+func (f GlyphMatcherFunc) Match(special, standard GlyphData) (bool, float64) {
+	return f(special, standard)
+}
+
+// OutlineMatcher 返回内置的逐点比较，每个坐标最多相差 tolerance、曲线标志必须相同，便于与自定义的比较组合使用
+func OutlineMatcher(tolerance fixed.Int26_6) GlyphMatcher {
+	return GlyphMatcherFunc(func(special, standard GlyphData) (bool, float64) {
+		return compareGlyphOutlines(
+			&outline{points: special.Points, ends: special.Ends},
+			&outline{points: standard.Points, ends: standard.Ends},
+			tolerance, false,
+		)
+	})
+}
+
+// AnyMatcher 把多个 matcher 组合为一个：依次尝试，使用第一个认为一致的 matcher 的结果，全部不一致时才不匹配。
+// 与 WithMatchers 中全部都要一致的组合方式相反，适合先用便宜的比较、失败后再退而使用代价高的比较，例如
+// AnyMatcher(OutlineMatcher(10), SSIMMatcher(64, 0.9))
+func AnyMatcher(matchers ...GlyphMatcher) GlyphMatcher {
+	return GlyphMatcherFunc(func(special, standard GlyphData) (bool, float64) {
+		for _, m := range matchers {
+			if matched, deviation := m.Match(special, standard); matched {
+				return true, deviation
+			}
+		}
+		return false, math.Inf(1)
+	})
+}
+
+// WeightedMatcher 是 EnsembleMatcher 中的一个 matcher 及其权重
+type WeightedMatcher struct {
+	Matcher GlyphMatcher
+	Weight  float64
+}
+
+// EnsembleMatcher 按权重混合多个 matcher 的得分（每个 matcher 的偏差按 MappingResult.Score 的定义换算为 0~1，
+// 不匹配的记为 0），加权平均不低于 threshold 时认为一致，返回的偏差换算回得分时就是这个加权平均。
+// 不同的混淆方式会让不同的单一比较失效，混合之后更稳健，例如：
+//
+//	EnsembleMatcher(0.6,
+//		WeightedMatcher{Matcher: OutlineMatcher(64), Weight: 0.5},
+//		WeightedMatcher{Matcher: PerceptualHashMatcher(8), Weight: 0.3},
+//		WeightedMatcher{Matcher: HuMomentMatcher(0.5), Weight: 0.2})
+func EnsembleMatcher(threshold float64, matchers ...WeightedMatcher) GlyphMatcher {
+	return GlyphMatcherFunc(func(special, standard GlyphData) (bool, float64) {
+		var score, total float64
+		for _, m := range matchers {
+			total += m.Weight
+			if matched, deviation := m.Matcher.Match(special, standard); matched {
+				score += m.Weight * similarityScore(deviation)
+			}
+		}
+		if total <= 0 {
+			return false, math.Inf(1)
+		}
+		score /= total
+		if score < threshold || score == 0 {
+			return false, math.Inf(1)
+		}
+		return true, 1/score - 1
+	})
+}
+
+// WithMatchers 用给定的比较方式代替内置的逐点比较。只有全部 matcher 都认为一致时两个字形才匹配，
+// 偏差取其中最大的一个。不传入 matcher 时恢复内置的比较
+func WithMatchers(matchers ...GlyphMatcher) Option {
+	return func(g *GlyphOutlineMapper) {
+		g.matchers = matchers
+	}
+}
+
+// SetCompareFunc 用 compare 代替内置的逐点比较，compare 的返回值与 GlyphMatcher.Match 相同。
+// GlyphBuf 中只有 Points 和 Ends 有效，坐标是按比较尺寸加载后的 26.6 定点数。
+// compare 为 nil 时恢复内置的比较。与 WithMatchers 互相覆盖，不能与映射并发调用
+func (g *GlyphOutlineMapper) SetCompareFunc(compare func(a, b *truetype.GlyphBuf) (bool, float64)) {
+	g.matchers = nil
+	if compare != nil {
+		g.matchers = []GlyphMatcher{GlyphMatcherFunc(func(special, standard GlyphData) (bool, float64) {
+			return compare(
+				&truetype.GlyphBuf{Points: special.Points, Ends: special.Ends},
+				&truetype.GlyphBuf{Points: standard.Points, Ends: standard.Ends},
+			)
+		})}
+	}
+	// 已经缓存的解码结果是按原来的比较方式得到的
+	g.resetCache()
+}
+
+// glyphData 把缓存的字形转换为 GlyphData，轮廓数据与缓存共享，不会复制
+func (c *cachedGlyph) glyphData() GlyphData {
+	return GlyphData{Rune: c.r, Font: c.font, Points: c.outline.points, Ends: c.outline.ends}
+}
+
+// matchWithMatchers 依次调用 WithMatchers 设置的 matcher，任意一个不匹配时立即返回
+func (g *GlyphOutlineMapper) matchWithMatchers(special, standard *cachedGlyph) (bool, float64) {
+	a, b := special.glyphData(), standard.glyphData()
+	deviation := 0.0
+	for _, m := range g.matchers {
+		matched, d := m.Match(a, b)
+		if !matched {
+			return false, math.Inf(1)
+		}
+		deviation = max(deviation, d)
+	}
+	return true, deviation
+}
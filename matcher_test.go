@@ -0,0 +1,117 @@
+package mapper
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+)
+
+func TestGlyphOutlineMapper_WithMatchers(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	// 'A' 的大小不同，'B' 与特殊字形完全一致
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 300)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+
+	// 只比较轮廓点数，两个候选都匹配，FirstMatch 返回 'A'
+	samePoints := GlyphMatcherFunc(func(special, standard GlyphData) (bool, float64) {
+		if special.Font != "special" || standard.Font != "standard" {
+			t.Errorf("unexpected fonts %q, %q", special.Font, standard.Font)
+		}
+		return len(special.Points) == len(standard.Points), 0
+	})
+	rejectA := GlyphMatcherFunc(func(_, standard GlyphData) (bool, float64) {
+		if standard.Rune == 'A' {
+			return false, math.Inf(1)
+		}
+		return true, 0
+	})
+
+	tests := []struct {
+		name     string
+		matchers []GlyphMatcher
+		want     rune
+	}{
+		{"point count", []GlyphMatcher{samePoints}, 'A'},
+		{"combined", []GlyphMatcher{samePoints, rejectA}, 'B'},
+		{"outline", []GlyphMatcher{OutlineMatcher(10)}, 'B'},
+	}
+	for _, tt := range tests {
+		mapper, err := NewGlyphOutlineMapper(special, standard, WithMatchers(tt.matchers...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, got, ok := mapper.MappingRune(0xE000); !ok || got != tt.want {
+			t.Errorf("%s: got %q (ok=%v), want %q", tt.name, got, ok, tt.want)
+		}
+	}
+}
+
+func TestGlyphOutlineMapper_SetCompareFunc(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 300)}, advance: 800},
+	}, map[rune]rune{'A': 1})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := mapper.MappingRune(0xE000); ok {
+		t.Fatal("matched with the built-in comparison")
+	}
+
+	mapper.SetCompareFunc(func(a, b *truetype.GlyphBuf) (bool, float64) {
+		return len(a.Points) == len(b.Points) && len(a.Ends) == len(b.Ends), 0
+	})
+	if _, got, ok := mapper.MappingRune(0xE000); !ok || got != 'A' {
+		t.Errorf("got %q (ok=%v), want 'A'", got, ok)
+	}
+
+	mapper.SetCompareFunc(nil)
+	if _, _, ok := mapper.MappingRune(0xE000); ok {
+		t.Error("matched after restoring the built-in comparison")
+	}
+}
+
+func TestEnsembleMatcher(t *testing.T) {
+	fixed := func(matched bool, deviation float64) GlyphMatcher {
+		return GlyphMatcherFunc(func(_, _ GlyphData) (bool, float64) { return matched, deviation })
+	}
+	// 得分分别为 1、0.5 和 0（不匹配）
+	parts := []WeightedMatcher{
+		{Matcher: fixed(true, 0), Weight: 0.5},
+		{Matcher: fixed(true, 1), Weight: 0.3},
+		{Matcher: fixed(false, math.Inf(1)), Weight: 0.2},
+	}
+	tests := []struct {
+		threshold float64
+		want      bool
+	}{
+		{0.6, true},
+		{0.65, true},
+		{0.7, false},
+	}
+	for _, tt := range tests {
+		matched, deviation := EnsembleMatcher(tt.threshold, parts...).Match(GlyphData{}, GlyphData{})
+		if matched != tt.want {
+			t.Errorf("threshold %v: matched = %v, want %v", tt.threshold, matched, tt.want)
+		}
+		if matched && math.Abs(similarityScore(deviation)-0.65) > 1e-9 {
+			t.Errorf("threshold %v: score = %v, want 0.65", tt.threshold, similarityScore(deviation))
+		}
+	}
+	if matched, _ := EnsembleMatcher(0).Match(GlyphData{}, GlyphData{}); matched {
+		t.Error("an empty ensemble should not match")
+	}
+
+	o := GlyphData{Points: []truetype.Point{{X: 0, Y: 0}, {X: 6400, Y: 0}, {X: 6400, Y: 6400}, {X: 0, Y: 6400}}, Ends: []int{4}}
+	if matched, deviation := PerceptualHashMatcher(0).Match(o, o); !matched || deviation != 0 {
+		t.Errorf("PerceptualHashMatcher on identical glyphs = %v, %v", matched, deviation)
+	}
+}
@@ -0,0 +1,65 @@
+package mapper
+
+import (
+	"expvar"
+	"time"
+)
+
+// Metrics 接收映射过程中的计数和耗时，用于在服务端监控映射的吞吐量。实现需要可以并发调用。
+// 子包 prommetrics 提供导出为 Prometheus 计数器和直方图的实现，ExpvarMetrics 发布到 expvar
+type Metrics interface {
+	// AddCompared 记录比较过的标准字形数量
+	AddCompared(n int)
+	// AddCacheHit 记录 MapString 等解码时命中字符缓存的次数
+	AddCacheHit()
+	// AddMatch 记录找到匹配的特殊字符
+	AddMatch()
+	// AddError 记录加载失败或比较超时的字形
+	AddError()
+	// ObserveCompareLatency 记录单个特殊字符与候选比较的耗时
+	ObserveCompareLatency(d time.Duration)
+}
+
+// WithMetrics 把映射过程中的计数和耗时报告给 m，默认不报告
+func WithMetrics(m Metrics) Option {
+	return func(g *GlyphOutlineMapper) {
+		if m == nil {
+			m = nopMetrics{}
+		}
+		g.metrics = m
+	}
+}
+
+// nopMetrics 是默认的 Metrics，丢弃所有数据
+type nopMetrics struct{}
+
+func (nopMetrics) AddCompared(int)                     {}
+func (nopMetrics) AddCacheHit()                        {}
+func (nopMetrics) AddMatch()                           {}
+func (nopMetrics) AddError()                           {}
+func (nopMetrics) ObserveCompareLatency(time.Duration) {}
+
+// ExpvarMetrics 返回把数据发布到 expvar 的 Metrics，变量名为 name，
+// 包含 compared、cache_hits、matches、errors、compare_count 和 compare_nanoseconds 几个计数。
+// 同名的变量已经存在时复用它，因此多个 mapper 可以共享同一组计数
+func ExpvarMetrics(name string) Metrics {
+	m, ok := expvar.Get(name).(*expvar.Map)
+	if !ok {
+		m = expvar.NewMap(name)
+	}
+	return expvarMetrics{m}
+}
+
+type expvarMetrics struct {
+	m *expvar.Map
+}
+
+func (e expvarMetrics) AddCompared(n int) { e.m.Add("compared", int64(n)) }
+func (e expvarMetrics) AddCacheHit()      { e.m.Add("cache_hits", 1) }
+func (e expvarMetrics) AddMatch()         { e.m.Add("matches", 1) }
+func (e expvarMetrics) AddError()         { e.m.Add("errors", 1) }
+
+func (e expvarMetrics) ObserveCompareLatency(d time.Duration) {
+	e.m.Add("compare_count", 1)
+	e.m.Add("compare_nanoseconds", int64(d))
+}
@@ -0,0 +1,59 @@
+package mapper
+
+import (
+	"expvar"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingMetrics 记录每个方法的调用结果
+type countingMetrics struct {
+	mu                                        sync.Mutex
+	compared, cacheHits, matches, errs, calls int
+}
+
+func (m *countingMetrics) AddCompared(n int) { m.mu.Lock(); m.compared += n; m.mu.Unlock() }
+func (m *countingMetrics) AddCacheHit()      { m.mu.Lock(); m.cacheHits++; m.mu.Unlock() }
+func (m *countingMetrics) AddMatch()         { m.mu.Lock(); m.matches++; m.mu.Unlock() }
+func (m *countingMetrics) AddError()         { m.mu.Lock(); m.errs++; m.mu.Unlock() }
+func (m *countingMetrics) ObserveCompareLatency(time.Duration) {
+	m.mu.Lock()
+	m.calls++
+	m.mu.Unlock()
+}
+
+func TestWithMetrics(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 0, 500)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+
+	metrics := &countingMetrics{}
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithMetrics(metrics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapper.MapString("\ue000\ue001\ue000")
+	// U+E000 比较 'A' 后匹配 'B'，U+E001 两个候选都比较过但没有匹配，第二个 U+E000 命中缓存
+	if metrics.compared != 4 || metrics.matches != 1 || metrics.cacheHits != 1 || metrics.calls != 2 || metrics.errs != 0 {
+		t.Errorf("got %+v", metrics)
+	}
+
+	exported, err := NewGlyphOutlineMapper(special, standard, WithMetrics(ExpvarMetrics("font_mapper_test")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exported.Mapping(0xE000, 0xE001)
+	vars := expvar.Get("font_mapper_test").(*expvar.Map)
+	if got := vars.Get("matches").String(); got != "1" {
+		t.Errorf("expvar matches = %s, want 1", got)
+	}
+	if got := vars.Get("compare_count").String(); got != "2" {
+		t.Errorf("expvar compare_count = %s, want 2", got)
+	}
+}
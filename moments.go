@@ -0,0 +1,91 @@
+package mapper
+
+import (
+	"math"
+
+	"github.com/golang/freetype/truetype"
+)
+
+// HuMomentMatcher 返回按 Hu 不变矩比较的 GlyphMatcher。七个不变矩由填充后字形的面积矩算出，
+// 与平移、等比缩放和旋转无关，计算只需要遍历一遍轮廓，适合放在精确比较之前作为廉价的初步筛选，例如
+// WithMatchers(HuMomentMatcher(0.1), OutlineMatcher(10))。tolerance 是每个不变矩取对数后允许的最大差值，
+// 0.1 大约对应 25% 的相对误差；形状越复杂，不变矩越能区分字形
+func HuMomentMatcher(tolerance float64) GlyphMatcher {
+	return GlyphMatcherFunc(func(special, standard GlyphData) (bool, float64) {
+		a, b := huMoments(special.Points, special.Ends), huMoments(standard.Points, standard.Ends)
+		var distance float64
+		for i := range a {
+			distance = max(distance, math.Abs(logMoment(a[i])-logMoment(b[i])))
+		}
+		if distance > tolerance {
+			return false, math.Inf(1)
+		}
+		return true, relativeDeviation(distance, 1, tolerance)
+	})
+}
+
+// logMoment 把不变矩换算到对数尺度，保留符号。绝对值小于 1e-15 的不变矩（例如对称字形的高阶不变矩）
+// 只是浮点误差，统一视为 0，避免符号随误差翻转
+func logMoment(h float64) float64 {
+	magnitude := max(math.Log10(math.Abs(h))+15, 0)
+	if h < 0 {
+		return -magnitude
+	}
+	return magnitude
+}
+
+// huMoments 计算填充后字形的七个 Hu 不变矩。面积矩由展开后的折线按格林公式逐边累加，
+// 外轮廓与内轮廓的方向相反，洞会自动被扣除。没有面积的字形返回全 0
+func huMoments(points []truetype.Point, ends []int) [7]float64 {
+	var m00, m10, m01, m20, m02, m11, m30, m03, m21, m12 float64
+	bounds := glyphBounds(GlyphData{Points: points}, [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)})
+	flatness := max(bounds[2]-bounds[0], bounds[3]-bounds[1]) / 1000
+	start := 0
+	for _, end := range ends {
+		poly := flattenContour(points[start:end], flatness)
+		start = end
+		for i := range poly {
+			p, q := poly[i], poly[(i+1)%len(poly)]
+			c := p.x*q.y - q.x*p.y
+			m00 += c / 2
+			m10 += (p.x + q.x) * c / 6
+			m01 += (p.y + q.y) * c / 6
+			m20 += (p.x*p.x + p.x*q.x + q.x*q.x) * c / 12
+			m02 += (p.y*p.y + p.y*q.y + q.y*q.y) * c / 12
+			m11 += (p.x*q.y + 2*p.x*p.y + 2*q.x*q.y + q.x*p.y) * c / 24
+			m30 += (p.x*p.x*p.x + p.x*p.x*q.x + p.x*q.x*q.x + q.x*q.x*q.x) * c / 20
+			m03 += (p.y*p.y*p.y + p.y*p.y*q.y + p.y*q.y*q.y + q.y*q.y*q.y) * c / 20
+			m21 += (p.x*p.x*(3*p.y+q.y) + 2*p.x*q.x*(p.y+q.y) + q.x*q.x*(p.y+3*q.y)) * c / 60
+			m12 += (p.y*p.y*(3*p.x+q.x) + 2*p.y*q.y*(p.x+q.x) + q.y*q.y*(p.x+3*q.x)) * c / 60
+		}
+	}
+	if m00 == 0 {
+		return [7]float64{}
+	}
+	if m00 < 0 {
+		// TrueType 的外轮廓是顺时针的，面积为负
+		m00, m10, m01, m20, m02, m11, m30, m03, m21, m12 = -m00, -m10, -m01, -m20, -m02, -m11, -m30, -m03, -m21, -m12
+	}
+
+	// 中心矩，再按面积归一化
+	xc, yc := m10/m00, m01/m00
+	eta := func(mu float64, order int) float64 { return mu / math.Pow(m00, 1+float64(order)/2) }
+	n20 := eta(m20-xc*m10, 2)
+	n02 := eta(m02-yc*m01, 2)
+	n11 := eta(m11-xc*m01, 2)
+	n30 := eta(m30-3*xc*m20+2*xc*xc*m10, 3)
+	n03 := eta(m03-3*yc*m02+2*yc*yc*m01, 3)
+	n21 := eta(m21-2*xc*m11-yc*m20+2*xc*xc*m01, 3)
+	n12 := eta(m12-2*yc*m11-xc*m02+2*yc*yc*m10, 3)
+
+	a, b := n30+n12, n21+n03
+	return [7]float64{
+		n20 + n02,
+		(n20-n02)*(n20-n02) + 4*n11*n11,
+		(n30-3*n12)*(n30-3*n12) + (3*n21-n03)*(3*n21-n03),
+		a*a + b*b,
+		(n30-3*n12)*a*(a*a-3*b*b) + (3*n21-n03)*b*(3*a*a-b*b),
+		(n20-n02)*(a*a-b*b) + 4*n11*a*b,
+		(3*n21-n03)*a*(a*a-3*b*b) - (n30-3*n12)*b*(3*a*a-b*b),
+	}
+}
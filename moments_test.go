@@ -0,0 +1,62 @@
+package mapper
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHuMomentMatcher(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{rotatedLShape()}, advance: 1000},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(0, 0, 600)}, advance: 1000},
+		{contours: [][]testPoint{triangle(0, 0, 600)}, advance: 1000},
+		{contours: [][]testPoint{lShape()}, advance: 1000},
+	}, map[rune]rune{'S': 1, 'T': 2, 'L': 3})
+
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithMatchers(HuMomentMatcher(0.1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, standardRune, ok := mapper.MappingRune(0xE000); !ok || standardRune != 'L' {
+		t.Errorf("got %q (ok=%v), want 'L'", standardRune, ok)
+	}
+
+	// 作为初步筛选时，通过筛选的候选仍然需要逐点一致
+	filtered, err := NewGlyphOutlineMapper(special, standard, WithMatchers(HuMomentMatcher(0.1), OutlineMatcher(10)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, standardRune, ok := filtered.MappingRune(0xE000); ok {
+		t.Errorf("rotated glyph matched %q after point comparison", standardRune)
+	}
+}
+
+func TestFingerprint_Moments(t *testing.T) {
+	font := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{lShape()}, advance: 1000},
+		{contours: [][]testPoint{rotatedLShape()}, advance: 1000},
+		{contours: [][]testPoint{square(0, 0, 600)}, advance: 1000},
+	}, map[rune]rune{'A': 1, 'B': 2, 'C': 3})
+	var moments [3][7]float64
+	for i, r := range "ABC" {
+		fp, err := Fingerprint(font, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		moments[i] = fp.Moments
+	}
+	if moments[0][0] <= 0 {
+		t.Fatalf("first moment %v, want positive", moments[0][0])
+	}
+	for i, m := range moments[0] {
+		if math.Abs(m-moments[1][i]) > 1e-3*math.Abs(m)+1e-12 {
+			t.Errorf("moment %d: %v != %v for the rotated shape", i+1, m, moments[1][i])
+		}
+	}
+	// 正方形的第一个不变矩是 1/6
+	if math.Abs(moments[2][0]-1.0/6) > 1e-9 {
+		t.Errorf("square first moment %v, want 1/6", moments[2][0])
+	}
+}
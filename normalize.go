@@ -0,0 +1,227 @@
+package mapper
+
+import (
+	"math"
+	"slices"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/math/fixed"
+)
+
+// WithTranslationInvariance 在比较之前把每个字形平移到其边界框的左下角与原点重合，
+// 混淆时改动了字形的左右间距、整体平移了轮廓的字形仍然可以逐点匹配。
+// 代价是形状相同、只是位置不同的字形（例如上标和下标数字）无法再区分
+func WithTranslationInvariance() Option {
+	return func(g *GlyphOutlineMapper) {
+		g.translationInvariant = true
+	}
+}
+
+// WithScaleInvariance 在比较之前把每个字形平移到原点，并等比缩放到边界框较长的一边为 1 em，
+// 混淆时整体放大或缩小了几个百分点的字形仍然可以逐点匹配，容差相对于缩放后的字形计算。
+// 开启后同时具有 WithTranslationInvariance 的效果，形状相同、只是大小不同的字形（例如大小写的 o）无法再区分
+func WithScaleInvariance() Option {
+	return func(g *GlyphOutlineMapper) {
+		g.scaleInvariant = true
+	}
+}
+
+// WithDirectionInvariance 在比较之前把每个轮廓统一为逆时针方向（y 轴向上），顺时针的轮廓保留起点、反转其余点的顺序。
+// 有些字体工具重新导出字体时会反转轮廓方向，开启后这样的字形仍然可以逐点匹配。
+// 代价是外轮廓和内部镂空的方向不再有区别，只靠点的位置区分
+func WithDirectionInvariance() Option {
+	return func(g *GlyphOutlineMapper) {
+		g.directionInvariant = true
+	}
+}
+
+// WithStartPointInvariance 逐点比较时允许每个轮廓的起点不同：两个点数相同的轮廓，只要其中一个循环移位之后
+// 逐点在容差之内就认为一致，取偏差最小的移位。重新编译字体时经常会改变轮廓的起点，点的顺序不变。
+// 只影响内置的逐点比较（包括 WithFloatCoordinates 的浮点比较），每个轮廓最坏需要尝试全部移位，比按下标比较慢
+func WithStartPointInvariance() Option {
+	return func(g *GlyphOutlineMapper) {
+		g.startPointInvariant = true
+	}
+}
+
+// WithContourPermutation 逐点比较时允许两个字形的轮廓以不同的顺序出现：按边界框和逐点偏差为每个轮廓贪心地找到对应的轮廓。
+// 加载时轮廓已经按边界框排序，默认只有边界框的每条边都只差容差以内、排序结果可能不同的轮廓之间才会重新对应；
+// 开启后不再限制，重新导出字体时调整了轮廓顺序的字形也可以匹配。只影响内置的逐点比较，可以与 WithStartPointInvariance 同时使用
+func WithContourPermutation() Option {
+	return func(g *GlyphOutlineMapper) {
+		g.contourPermutation = true
+	}
+}
+
+// WithCurveFlattening 在比较之前把每个轮廓中的二次贝塞尔曲线展开为折线，并去掉直线中间多余的点，
+// 隐含的曲线上的点被显式写出、或者直线被拆成几段的字形，展开后与原来的字形点序一致，可以逐点比较。
+// 展开的精度由 SetFlatness 设置，与重新采样和形状签名相同
+func WithCurveFlattening() Option {
+	return func(g *GlyphOutlineMapper) {
+		g.curveFlattening = true
+	}
+}
+
+// WithContourResampling 在比较之前把每个轮廓展开为折线，再从最靠近左下方的顶点开始沿弧长均匀采样为 n 个点。
+// 逐点比较要求两个字形的点数完全相同，混淆时增删了控制点、但形状不变的字形采样后点数一致，可以逐点匹配。
+// n 越大越精确，比较也越慢，32~64 通常足够；容差需要能覆盖采样位置的细微漂移
+func WithContourResampling(n int) Option {
+	return func(g *GlyphOutlineMapper) {
+		g.resampleCount = max(n, 0)
+	}
+}
+
+// outlineNormalization 是影响 normalizeOutline 结果的全部配置，用于判断保存下来的数据是否按同样的方式归一化
+type outlineNormalization struct {
+	Translation, Scale, Direction, CurveFlattening bool
+	Resample                                       int
+	Flatness                                       float64
+}
+
+func (g *GlyphOutlineMapper) outlineNormalization() outlineNormalization {
+	n := outlineNormalization{
+		Translation:     g.translationInvariant,
+		Scale:           g.scaleInvariant,
+		Direction:       g.directionInvariant,
+		Resample:        g.resampleCount,
+		CurveFlattening: g.curveFlattening,
+	}
+	if g.resampleCount > 0 || g.curveFlattening {
+		n.Flatness = g.flatness // 只有展开曲线和重新采样时用到
+	}
+	return n
+}
+
+// normalizeOutline 按开启的归一化方式返回变换后的轮廓副本，没有开启时原样返回 o。
+// em 是 1 em 在轮廓坐标中的长度，用于缩放和换算展开曲线的精度。o 可能来自 StandardIndex 并被多个 mapper 共用，不能原地修改
+func (g *GlyphOutlineMapper) normalizeOutline(o *outline, em fixed.Int26_6) *outline {
+	if g.directionInvariant {
+		o = orientOutline(o)
+	}
+	if g.curveFlattening {
+		o = flattenOutline(o, g.flatness/1000*float64(em))
+	}
+	if g.resampleCount > 0 {
+		o = resampleOutline(o, g.resampleCount, g.flatness/1000*float64(em))
+	}
+	if !g.translationInvariant && !g.scaleInvariant || len(o.points) == 0 {
+		return o
+	}
+	minX, minY, maxX, maxY := o.points[0].X, o.points[0].Y, o.points[0].X, o.points[0].Y
+	for _, p := range o.points[1:] {
+		minX, minY = min(minX, p.X), min(minY, p.Y)
+		maxX, maxY = max(maxX, p.X), max(maxY, p.Y)
+	}
+	num, den := int64(1), int64(1)
+	if size := max(maxX-minX, maxY-minY); g.scaleInvariant && size > 0 {
+		num, den = int64(em), int64(size)
+	}
+	points := make([]truetype.Point, len(o.points))
+	for i, p := range o.points {
+		points[i] = truetype.Point{X: scaleCoord(p.X-minX, num, den), Y: scaleCoord(p.Y-minY, num, den), Flags: p.Flags}
+	}
+	return &outline{points: points, ends: o.ends}
+}
+
+// orientOutline 把顺时针的轮廓反转为逆时针，方向按包括控制点在内的多边形的有向面积判断。
+// 全部轮廓都已经是逆时针时原样返回 o
+func orientOutline(o *outline) *outline {
+	var reversed *outline
+	start := 0
+	for _, end := range o.ends {
+		contour := o.points[start:end]
+		var area int64
+		for i, p := range contour {
+			q := contour[(i+1)%len(contour)]
+			area += int64(p.X)*int64(q.Y) - int64(q.X)*int64(p.Y)
+		}
+		if area < 0 {
+			if reversed == nil {
+				reversed = &outline{points: slices.Clone(o.points), ends: o.ends}
+			}
+			// 起点不变，其余的点倒序
+			slices.Reverse(reversed.points[start+1 : end])
+		}
+		start = end
+	}
+	if reversed == nil {
+		return o
+	}
+	return reversed
+}
+
+// scaleCoord 返回 v*num/den 四舍五入的结果，v 不为负
+func scaleCoord(v fixed.Int26_6, num, den int64) fixed.Int26_6 {
+	return fixed.Int26_6((int64(v)*num + den/2) / den)
+}
+
+// resampleOutline 把每个轮廓沿弧长重新采样为 n 个曲线上的点，退化为一个点的轮廓重复该点。
+// 点的方向保持不变，起点取 x+y 最小（相同时 y 最小）的顶点
+func resampleOutline(o *outline, n int, flatness float64) *outline {
+	resampled := &outline{points: make([]truetype.Point, 0, n*len(o.ends)), ends: make([]int, 0, len(o.ends))}
+	start := 0
+	for _, end := range o.ends {
+		poly := flattenContour(o.points[start:end], flatness)
+		start = end
+		if len(poly) == 0 {
+			continue
+		}
+		poly = lowerLeftFirst(poly)
+		var perimeter float64
+		for i := range poly {
+			perimeter += poly[(i+1)%len(poly)].sub(poly[i]).len()
+		}
+		samples := resampleContour(poly, perimeter, n)
+		for i := range n {
+			v := poly[0]
+			if len(samples) == n {
+				v = samples[i]
+			}
+			resampled.points = append(resampled.points, truetype.Point{X: fixed.Int26_6(math.Round(v.x)), Y: fixed.Int26_6(math.Round(v.y)), Flags: 1})
+		}
+		resampled.ends = append(resampled.ends, len(resampled.points))
+	}
+	return resampled
+}
+
+// flattenOutline 把每个轮廓展开为只有曲线上的点的折线，去掉共线的中间点，起点与 resampleOutline 相同
+func flattenOutline(o *outline, flatness float64) *outline {
+	flattened := &outline{points: make([]truetype.Point, 0, len(o.points)), ends: make([]int, 0, len(o.ends))}
+	start := 0
+	for _, end := range o.ends {
+		poly := flattenContour(o.points[start:end], flatness)
+		start = end
+		if len(poly) == 0 {
+			continue
+		}
+		var kept []vec
+		for i, v := range poly {
+			prev, next := poly[(i+len(poly)-1)%len(poly)], poly[(i+1)%len(poly)]
+			a, b := v.sub(prev), next.sub(v)
+			// 与前后两点共线并且方向相同的点不改变形状
+			if len(poly) > 2 && math.Abs(a.x*b.y-a.y*b.x) <= 1e-9*(a.len()*b.len()) && a.x*b.x+a.y*b.y >= 0 {
+				continue
+			}
+			kept = append(kept, v)
+		}
+		if len(kept) == 0 {
+			kept = poly
+		}
+		for _, v := range lowerLeftFirst(kept) {
+			flattened.points = append(flattened.points, truetype.Point{X: fixed.Int26_6(math.Round(v.x)), Y: fixed.Int26_6(math.Round(v.y)), Flags: 1})
+		}
+		flattened.ends = append(flattened.ends, len(flattened.points))
+	}
+	return flattened
+}
+
+// lowerLeftFirst 把闭合折线旋转为从 x+y 最小（相同时 y 最小）的顶点开始，不同编码的同一个轮廓起点可能不同
+func lowerLeftFirst(poly []vec) []vec {
+	first := 0
+	for i, v := range poly {
+		if f := poly[first]; v.x+v.y < f.x+f.y || v.x+v.y == f.x+f.y && v.y < f.y {
+			first = i
+		}
+	}
+	return slices.Concat(poly[first:], poly[:first])
+}
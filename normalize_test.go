@@ -0,0 +1,208 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+)
+
+func TestWithTranslationInvariance(t *testing.T) {
+	// 特殊字形整体向右上平移了 60 个单位，远超默认的容差
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(160, 160, 500)}, advance: 860},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+
+	plain, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, standardRune, ok := plain.MappingRune(0xE000); ok {
+		t.Errorf("shifted glyph matched %q without translation invariance", standardRune)
+	}
+
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithTranslationInvariance())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mapper.Config().TranslationInvariant {
+		t.Error("Config().TranslationInvariant = false")
+	}
+	if _, standardRune, ok := mapper.MappingRune(0xE000); !ok || standardRune != 'B' {
+		t.Errorf("got %q (ok=%v), want 'B'", standardRune, ok)
+	}
+	if equal, err := mapper.GlyphOutlineEqualAt(0xE000, 'B', 2000, 20); err != nil || !equal {
+		t.Errorf("GlyphOutlineEqualAt = %v, %v, want true", equal, err)
+	}
+}
+
+func TestWithTranslationInvariance_StandardIndex(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(160, 160, 500)}, advance: 860},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1})
+
+	// 用开启了平移不变的配置建立的索引，仍然可以给不开启的 mapper 使用
+	index, err := BuildStandardIndex(standard, WithTranslationInvariance())
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := NewGlyphOutlineMapper(special, standard, WithStandardIndex(index))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, standardRune, ok := plain.MappingRune(0xE000); ok {
+		t.Errorf("shifted glyph matched %q without translation invariance", standardRune)
+	}
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithStandardIndex(index), WithTranslationInvariance())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, standardRune, ok := mapper.MappingRune(0xE000); !ok || standardRune != 'A' {
+		t.Errorf("got %q (ok=%v), want 'A'", standardRune, ok)
+	}
+}
+
+func TestWithScaleInvariance(t *testing.T) {
+	// 特殊字形放大了 4%，同时向右平移；标准字体中另有一个大小相同、形状不同的字形
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{{{150, 100, false}, {150, 620, false}, {410, 620, false}, {410, 100, false}}}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{{{100, 100, false}, {100, 600, false}, {350, 600, false}, {350, 100, false}}}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+
+	plain, err := NewGlyphOutlineMapper(special, standard, WithTranslationInvariance())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, standardRune, ok := plain.MappingRune(0xE000); ok {
+		t.Errorf("scaled glyph matched %q without scale invariance", standardRune)
+	}
+
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithScaleInvariance())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mapper.Config().ScaleInvariant {
+		t.Error("Config().ScaleInvariant = false")
+	}
+	if _, standardRune, ok := mapper.MappingRune(0xE000); !ok || standardRune != 'B' {
+		t.Errorf("got %q (ok=%v), want 'B'", standardRune, ok)
+	}
+	floatMapper, err := NewGlyphOutlineMapper(special, standard, WithScaleInvariance(), WithFloatCoordinates(0.001))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, standardRune, ok := floatMapper.MappingRune(0xE000); !ok || standardRune != 'B' {
+		t.Errorf("float coordinates: got %q (ok=%v), want 'B'", standardRune, ok)
+	}
+}
+
+func TestWithContourResampling(t *testing.T) {
+	// U+E000 是边上多了中点的正方形；U+E001 是全部由控制点组成的圆，把隐含的曲线上的点显式写了出来
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{{{100, 100, false}, {100, 350, false}, {100, 600, false}, {600, 600, false}, {600, 100, false}}}, advance: 800},
+		{contours: [][]testPoint{{{100, 100, true}, {100, 350, false}, {100, 600, true}, {350, 600, false}, {600, 600, true}, {600, 350, false}, {600, 100, true}, {350, 100, false}}}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{{{100, 100, true}, {100, 600, true}, {600, 600, true}, {600, 100, true}}}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2, 'O': 3})
+
+	plain, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := plain.Mapping(0xE000, 0xE001); len(got) != 0 {
+		t.Errorf("re-encoded glyphs matched without resampling: %v", got)
+	}
+
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithContourResampling(32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mapper.Config().ContourResampling; got != 32 {
+		t.Errorf("Config().ContourResampling = %d, want 32", got)
+	}
+	got := mapper.Mapping(0xE000, 0xE001)
+	if len(got) != 2 || got[0xE000] != 'B' || got[0xE001] != 'O' {
+		t.Errorf("got %v, want U+E000 => B, U+E001 => O", got)
+	}
+}
+
+func TestWithCurveFlattening(t *testing.T) {
+	// 与 TestWithContourResampling 相同的两个字形：多余的直线中点，以及显式写出的隐含曲线上的点
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{{{100, 100, false}, {100, 350, false}, {100, 600, false}, {600, 600, false}, {600, 100, false}}}, advance: 800},
+		{contours: [][]testPoint{{{100, 100, true}, {100, 350, false}, {100, 600, true}, {350, 600, false}, {600, 600, true}, {600, 350, false}, {600, 100, true}, {350, 100, false}}}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{{{100, 100, true}, {100, 600, true}, {600, 600, true}, {600, 100, true}}}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2, 'O': 3})
+
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithCurveFlattening())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config := mapper.Config(); !config.CurveFlattening || config.Flatness != defaultFlatness {
+		t.Errorf("Config() = %+v, want curve flattening at the default flatness", config)
+	}
+	got := mapper.Mapping(0xE000, 0xE001)
+	if len(got) != 2 || got[0xE000] != 'B' || got[0xE001] != 'O' {
+		t.Errorf("got %v, want U+E000 => B, U+E001 => O", got)
+	}
+
+	// 展开后的正方形只剩 4 个角
+	o := flattenOutline(&outline{points: []truetype.Point{
+		{X: 0, Y: 0, Flags: 1}, {X: 0, Y: 320, Flags: 1}, {X: 0, Y: 640, Flags: 1}, {X: 640, Y: 640, Flags: 1}, {X: 640, Y: 0, Flags: 1},
+	}, ends: []int{5}}, 64)
+	if len(o.points) != 4 || o.ends[0] != 4 {
+		t.Errorf("got %v, want the 4 corners", o.points)
+	}
+}
+
+func TestWithDirectionInvariance(t *testing.T) {
+	// 特殊字形的外轮廓与标准字形方向相反，起点相同；内部的小方块方向一致
+	reversed := square(100, 100, 500)
+	reversed[1], reversed[3] = reversed[3], reversed[1]
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{reversed, square(250, 250, 100)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500), square(250, 250, 100)}, advance: 800},
+	}, map[rune]rune{'A': 1})
+
+	plain, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.GlyphOutlineEqual(0xE000, 'A') {
+		t.Error("reversed contour matched without direction invariance")
+	}
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithDirectionInvariance())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mapper.GlyphOutlineEqual(0xE000, 'A') {
+		t.Error("reversed contour should match with direction invariance")
+	}
+	if !mapper.Config().DirectionInvariant {
+		t.Error("Config().DirectionInvariant = false")
+	}
+
+	// 已经是逆时针的轮廓原样返回
+	ccw := &outline{points: []truetype.Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}}, ends: []int{3}}
+	if orientOutline(ccw) != ccw {
+		t.Error("counter-clockwise outline should be returned unchanged")
+	}
+}
@@ -0,0 +1,83 @@
+package mapper
+
+import (
+	"image"
+	"image/color"
+	"log/slog"
+	"math"
+)
+
+// GlyphRecognizer 识别渲染好的单个字形，返回识别出的字符和 0~1 的置信度。实现需要可以并发调用。
+// 本包不依赖任何 OCR 引擎，子包 tesseract 提供调用 Tesseract 命令行程序的实现；
+// 也可以用 gosseract 等绑定实现：把 img 编码为 PNG 交给 SetImageFromBytes，页面分割模式设为单个字符（PSM_SINGLE_CHAR）
+type GlyphRecognizer interface {
+	Recognize(img image.Image) (r rune, confidence float64, err error)
+}
+
+// GlyphRecognizerFunc 让普通函数实现 GlyphRecognizer
+type GlyphRecognizerFunc func(img image.Image) (rune, float64, error)
+
+func (f GlyphRecognizerFunc) Recognize(img image.Image) (rune, float64, error) {
+	return f(img)
+}
+
+// ocrFont 是 OCR 得到的结果在 MappingResult.StandardFont 中使用的名字
+const ocrFont = "ocr"
+
+// WithOCRFallback 在轮廓比较（以及 WithTransforms、WithNearestFallback）都没有找到匹配时，把特殊字形渲染为
+// size×size 的白底黑字图像交给 recognizer 识别，识别结果作为 LowConfidence 的映射记录下来，Score 为识别的置信度，
+// StandardFont 为 "ocr"。重新绘制过字形、而不只是打乱编码的混淆字体只能这样破解。size 小于等于 0 时使用 64
+func WithOCRFallback(recognizer GlyphRecognizer, size int) Option {
+	return func(g *GlyphOutlineMapper) {
+		g.recognizer = recognizer
+		g.ocrSize = size
+		if size <= 0 {
+			g.ocrSize = 64
+		}
+	}
+}
+
+// recognizeMatch 用 WithOCRFallback 设置的 recognizer 识别特殊字形，识别失败或没有结果时返回 false
+func (g *GlyphOutlineMapper) recognizeMatch(special *cachedGlyph) (MappingResult, bool) {
+	if len(special.outline.points) == 0 {
+		return MappingResult{}, false
+	}
+	r, confidence, err := g.recognizer.Recognize(ocrImage(special.glyphData(), g.ocrSize))
+	if err != nil || r <= 0 {
+		if g.debugEnabled() {
+			g.logger.Debug("ocr failed", runeAttr("special", special.r), slog.Any("error", err))
+		}
+		return MappingResult{}, false
+	}
+	return MappingResult{
+		Special:       special.r,
+		Standard:      r,
+		StandardFont:  ocrFont,
+		Score:         min(max(confidence, 0), 1),
+		LowConfidence: true,
+	}, true
+}
+
+// ocrImage 把字形渲染为 size×size 的灰度图像，字形占中间的八成，四周留白便于 OCR 引擎定位
+func ocrImage(glyph GlyphData, size int) *image.Gray {
+	inner := max(size*4/5, 1)
+	coverage := rasterizeGlyph(glyph, squareBounds(glyphBounds(glyph, [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)})), inner)
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	offset := (size - inner) / 2
+	for y := range inner {
+		for x := range inner {
+			img.SetGray(x+offset, y+offset, color.Gray{Y: 0xff - coverage.AlphaAt(x, y).A})
+		}
+	}
+	return img
+}
+
+// squareBounds 把边界框扩展为以原中心为中心的正方形，渲染时字形保持宽高比并居中
+func squareBounds(b [4]float64) [4]float64 {
+	half := max(b[2]-b[0], b[3]-b[1]) / 2
+	cx, cy := (b[0]+b[2])/2, (b[1]+b[3])/2
+	return [4]float64{cx - half, cy - half, cx + half, cy + half}
+}
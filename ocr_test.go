@@ -0,0 +1,58 @@
+package mapper
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestWithOCRFallback(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 300)}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2, 0xE002: 3})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1})
+
+	calls := 0
+	recognizer := GlyphRecognizerFunc(func(img image.Image) (rune, float64, error) {
+		calls++
+		if img.Bounds() != image.Rect(0, 0, 32, 32) {
+			t.Errorf("image bounds = %v, want 32×32", img.Bounds())
+		}
+		// 四周留白，中间是黑色的字形
+		if c := color.GrayModel.Convert(img.At(0, 0)).(color.Gray); c.Y != 0xff {
+			t.Errorf("corner = %v, want white", c)
+		}
+		if c := color.GrayModel.Convert(img.At(16, 20)).(color.Gray); c.Y > 0x40 {
+			t.Errorf("center = %v, want dark", c)
+		}
+		if calls > 1 {
+			return 0, 0, errors.New("unreadable")
+		}
+		return '中', 0.7, nil
+	})
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithOCRFallback(recognizer, 32), WithConcurrency(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := mapper.MappingDetailed(0xE000, 0xE002)
+	if len(results) != 2 {
+		t.Fatalf("got %+v, want two results", results)
+	}
+	if results[0].Standard != 'A' || results[0].LowConfidence {
+		t.Errorf("outline match = %+v", results[0])
+	}
+	want := MappingResult{Special: 0xE001, Standard: '中', StandardFont: "ocr", Score: 0.7, LowConfidence: true}
+	if got := results[1]; got.Special != want.Special || got.Standard != want.Standard || got.StandardFont != want.StandardFont ||
+		got.Score != want.Score || !got.LowConfidence {
+		t.Errorf("ocr result = %+v, want %+v", got, want)
+	}
+	// U+E000 匹配了轮廓，不会交给 recognizer
+	if calls != 2 {
+		t.Errorf("recognizer called %d times, want 2", calls)
+	}
+}
@@ -0,0 +1,118 @@
+package mapper
+
+import (
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// Option 用于在创建 GlyphOutlineMapper 时调整其行为
+type Option func(*GlyphOutlineMapper)
+
+// WithConcurrency 设置 Mapping 时同时比较的字符数，默认为 10，效果与 SetConcurrent 相同
+func WithConcurrency(n int) Option {
+	return func(g *GlyphOutlineMapper) {
+		g.SetConcurrent(n)
+	}
+}
+
+// WithTolerance 设置逐点比较时每个坐标允许的误差，单位与 WithScale 加载后的 26.6 坐标相同，默认为 10
+func WithTolerance(tolerance fixed.Int26_6) Option {
+	return func(g *GlyphOutlineMapper) {
+		g.tolerance = tolerance
+		g.toleranceEm = -1
+	}
+}
+
+// WithHinting 设置加载字形时使用的 hinting 方式，默认为 font.HintingNone
+func WithHinting(hinting font.Hinting) Option {
+	return func(g *GlyphOutlineMapper) {
+		g.hinting = hinting
+	}
+}
+
+// WithScale 设置加载字形时 1 em 对应的 26.6 定点数，默认为 fixed.I(1000)。
+// 尺寸越大坐标的取整误差越小，调整尺寸时通常需要同时调整 WithTolerance；SetTolerance 设置的容差会按新的尺寸换算
+func WithScale(scale fixed.Int26_6) Option {
+	return func(g *GlyphOutlineMapper) {
+		g.scale = scale
+		g.applyToleranceEm()
+	}
+}
+
+// WithShapeSignatureMatching 改为使用轮廓形状签名比较字形，而不是逐点比较坐标。
+// threshold 为 0~1 之间的相似度阈值，越接近 1 越严格，签名的构造方式见 glyphSignature
+func WithShapeSignatureMatching(threshold float64) Option {
+	return func(g *GlyphOutlineMapper) {
+		g.shapeSignature = true
+		g.signatureThreshold = threshold
+	}
+}
+
+// WithFloatCoordinates 直接使用 glyf 表中的原始坐标（换算为以 em 为单位的 float64）进行比较，
+// 避免先按 1000 ppem 缩放取整再比较造成的二次量化。tolerance 同样以 em 为单位，例如 0.001。
+// 浮点比较支持 WithStartPointInvariance，但轮廓总是按加载时的顺序对应，WithContourPermutation 不起作用
+func WithFloatCoordinates(tolerance float64) Option {
+	return func(g *GlyphOutlineMapper) {
+		g.floatCoordinates = true
+		g.floatTolerance = tolerance
+	}
+}
+
+// WithMatchStrategy 设置存在多个匹配候选时的选择策略，默认为 FirstMatch
+func WithMatchStrategy(strategy MatchStrategy) Option {
+	return func(g *GlyphOutlineMapper) {
+		g.strategy = strategy
+	}
+}
+
+// WithProgress 在 Mapping 等批量映射过程中每比较完一个字符就调用一次 fn，
+// done 是已经完成的字符数，total 是本次映射的字符总数。fn 的调用是串行的，done 单调递增
+func WithProgress(fn func(done, total int)) Option {
+	return func(g *GlyphOutlineMapper) {
+		g.progress = fn
+	}
+}
+
+// WithOnMatch 在 Mapping 等批量映射过程中每找到一个匹配就调用一次 fn，score 与 MappingResult.Score 相同。
+// fn 的调用是串行的，但顺序不固定，适合在漫长的映射过程中记录或保存已经找到的结果
+func WithOnMatch(fn func(special, standard rune, score float64)) Option {
+	return func(g *GlyphOutlineMapper) {
+		g.onMatch = fn
+	}
+}
+
+// WithIgnorePointFlags 逐点比较时只比较坐标，不再要求对应的点同为曲线上的点或同为控制点。
+// 开启 WithCurveFlattening 或 WithContourResampling 时展开后的点都在曲线上，标志总是相同，不需要这个选项
+func WithIgnorePointFlags() Option {
+	return func(g *GlyphOutlineMapper) {
+		g.ignorePointFlags = true
+	}
+}
+
+// WithDeterministicOrder 让批量映射过程中的回调（MappingStream 的 channel、WithOnMatch）按特殊字符升序收到结果，
+// 相同的输入每次运行得到完全相同的输出序列。返回的映射结果本身总是按特殊字符排序、与并发无关。
+// 比较较慢的字符会拖住它之后已经完成的结果
+func WithDeterministicOrder() Option {
+	return func(g *GlyphOutlineMapper) {
+		g.deterministic = true
+	}
+}
+
+// WithNearestFallback 在没有候选字符落在容差之内时，退而返回偏差最小的候选，并把结果标记为
+// MappingResult.LowConfidence。Mapping 等只返回映射表的方法同样会包含这些结果，需要区分时使用 MappingDetailed
+func WithNearestFallback() Option {
+	return func(g *GlyphOutlineMapper) {
+		g.nearestFallback = true
+	}
+}
+
+// WithDeadline 限制单个特殊字符与候选字符比较的时间（不包括建立标准字体缓存的时间），
+// 避免点数极多的装饰性字形拖住整次映射。超时的字符不会出现在结果中，而是以 UnmappedTimeout 的原因出现在 Unmapped 的列表里，
+// MappingRuneE 对超时的字符返回包装 ErrCompareTimeout 的 *RuneError
+func WithDeadline(perRune time.Duration) Option {
+	return func(g *GlyphOutlineMapper) {
+		g.runeDeadline = perRune
+	}
+}
@@ -0,0 +1,49 @@
+package mapper
+
+import "sync"
+
+// resultOrder 把并发完成的结果按开始比较的顺序交给 emit，用于 WithDeterministicOrder。
+// 先完成的结果会被暂存，直到它前面的字符都比较完
+type resultOrder struct {
+	emit func(MappingResult)
+
+	mu      sync.Mutex
+	queue   []rune // 已经开始、但结果还没有交出去的字符，按开始的顺序排列
+	results map[rune]orderedResult
+}
+
+type orderedResult struct {
+	result MappingResult
+	ok     bool
+}
+
+func newResultOrder(emit func(MappingResult)) *resultOrder {
+	return &resultOrder{emit: emit, results: map[rune]orderedResult{}}
+}
+
+func (o *resultOrder) dispatch(r rune) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	o.queue = append(o.queue, r)
+	o.mu.Unlock()
+}
+
+// complete 记录 r 的结果，并交出队首所有已经完成的结果
+func (o *resultOrder) complete(r rune, result MappingResult, ok bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.results[r] = orderedResult{result, ok}
+	for len(o.queue) > 0 {
+		head, done := o.results[o.queue[0]]
+		if !done {
+			break
+		}
+		delete(o.results, o.queue[0])
+		o.queue = o.queue[1:]
+		if head.ok {
+			o.emit(head.result)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package mapper
+
+import (
+	"encoding/binary"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// WithOutlineHash 为每个归一化之后的字形计算规范化序列（按容差量化的坐标、轮廓端点和曲线标志）的 xxHash 哈希，
+// 查找时先在标准字体缓存中按哈希取出完全一致的字形，只比较这一个候选；哈希没有命中时才逐个比较。
+// 与 WithRawGlyphMatch 不同，它比较的是加载之后的轮廓，对 CFF 字体、复合字形和重新编码过的 glyf 数据同样有效。
+// 命中时不再比较其他候选，即使 FirstMatch 策略下排在前面的候选也在容差之内。
+// 用同样的容差和归一化方式生成的 StandardIndex 会保存标准字形的哈希，使用索引时不再重新计算
+func WithOutlineHash() Option {
+	return func(g *GlyphOutlineMapper) {
+		g.outlineHashing = true
+	}
+}
+
+// outlineHash 返回轮廓规范化序列的 XXH64 哈希。坐标按 step 向下取整量化，
+// 哈希相同的两个轮廓结构完全一致、每个坐标的差异都小于 step，step 取容差加一时逐点比较一定通过。
+// 没有轮廓的字形返回 0
+func outlineHash(o *outline, step fixed.Int26_6) uint64 {
+	if len(o.points) == 0 {
+		return 0
+	}
+	step = max(step, 1)
+	buf := make([]byte, 0, 8*len(o.points)+4*len(o.ends))
+	buf = binary.AppendUvarint(buf, uint64(len(o.ends)))
+	for _, end := range o.ends {
+		buf = binary.AppendUvarint(buf, uint64(end))
+	}
+	for _, p := range o.points {
+		buf = binary.AppendVarint(buf, int64(floorDiv(p.X, step)))
+		buf = binary.AppendVarint(buf, int64(floorDiv(p.Y, step)))
+		buf = append(buf, byte(p.Flags&1))
+	}
+	return xxhash64(buf)
+}
+
+// floorDiv 向负无穷取整的整数除法，b 必须为正数
+func floorDiv(a, b fixed.Int26_6) fixed.Int26_6 {
+	q := a / b
+	if a%b < 0 {
+		q--
+	}
+	return q
+}
+
+// indexOutlineHash 记录标准字形轮廓的哈希，同样的哈希只记录优先级最高的一个
+func (c *standardCache) indexOutlineHash(glyph *cachedGlyph) {
+	if glyph.outlineHash == 0 {
+		return
+	}
+	if _, ok := c.byOutlineHash[glyph.outlineHash]; !ok {
+		c.byOutlineHash[glyph.outlineHash] = glyph
+	}
+}
+
+// hashedMatch 按轮廓哈希查找与特殊字形一致的标准字形，并用配置的比较方式确认。
+// 同码位的字符哈希也相同时优先返回它，与完整的候选扫描一致
+func (g *GlyphOutlineMapper) hashedMatch(cache *standardCache, special *cachedGlyph) (MappingResult, bool) {
+	if special.outlineHash == 0 {
+		return MappingResult{}, false
+	}
+	standard := cache.byOutlineHash[special.outlineHash]
+	if identity := cache.byRune[special.r]; special.r != 0 && identity != nil && identity.outlineHash == special.outlineHash {
+		standard = identity
+	}
+	if standard == nil {
+		return MappingResult{}, false
+	}
+	g.metrics.AddCompared(1)
+	matched, deviation, _ := g.matchGlyphs(special, standard)
+	if !matched {
+		return MappingResult{}, false
+	}
+	return newMappingResult(special, standard, deviation), true
+}
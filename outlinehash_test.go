@@ -0,0 +1,57 @@
+package mapper
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+)
+
+func TestWithOutlineHash(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 0, 500)}, advance: 800},
+		{contours: [][]testPoint{square(200, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2, 'C': 3})
+
+	metrics := &countingMetrics{}
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithOutlineHash(), WithMetrics(metrics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, r, ok := mapper.MappingRune(0xE000); !ok || r != 'C' {
+		t.Errorf("U+E000: got %q (ok=%v), want 'C'", r, ok)
+	}
+	// 哈希命中时只比较一个候选
+	if metrics.compared != 1 {
+		t.Errorf("compared %d candidates, want 1", metrics.compared)
+	}
+	if _, _, ok := mapper.MappingRune(0xE001); ok {
+		t.Error("U+E001 should not match after the hash miss falls back to comparison")
+	}
+	if metrics.compared != 4 {
+		t.Errorf("compared %d candidates in total, want 4", metrics.compared)
+	}
+}
+
+func TestOutlineHash(t *testing.T) {
+	o := &outline{points: []truetype.Point{{X: 0, Y: 0, Flags: 1}, {X: -5, Y: 100}, {X: 100, Y: 100, Flags: 1}}, ends: []int{3}}
+	shifted := &outline{points: slices.Clone(o.points), ends: o.ends}
+	shifted.points[1].X = -4
+	if outlineHash(o, 1) == outlineHash(shifted, 1) {
+		t.Error("outlines differing by one unit should hash differently at step 1")
+	}
+	if outlineHash(o, 11) != outlineHash(shifted, 11) {
+		t.Error("-5 and -4 fall into the same bucket at step 11")
+	}
+	if floorDiv(-1, 11) != -1 || floorDiv(10, 11) != 0 || floorDiv(11, 11) != 1 {
+		t.Error("floorDiv should round toward negative infinity")
+	}
+	if outlineHash(&outline{}, 11) != 0 {
+		t.Error("empty outline should hash to 0")
+	}
+}
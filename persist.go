@@ -0,0 +1,172 @@
+package mapper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"unicode/utf8"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// mappingFileVersion 是 Save 写出的文件格式版本
+const mappingFileVersion = 1
+
+// SavedMapping 是从文件中读取的映射结果以及生成它时的字体和配置
+type SavedMapping struct {
+	Version          int
+	SpecialChecksum  string        // 特殊字体原始数据的 SHA-256，未知时为空
+	StandardChecksum string        // 标准字体原始数据的 SHA-256，未知时为空
+	Tolerance        fixed.Int26_6 // 生成映射时的逐点比较容差
+	CompareScale     fixed.Int26_6 // 生成映射时加载字形的尺寸
+
+	ExtraStandardChecksums map[string]string // AddStandardFont 追加的标准字体的名字到校验和，没有时为空
+	FloatCoordinates       bool              // 是否开启了 WithFloatCoordinates
+	FloatTolerance         float64           // 开启 WithFloatCoordinates 时的浮点容差，否则为 0
+	StartPointInvariant    bool              // 是否开启了 WithStartPointInvariance
+	ContourPermutation     bool              // 是否开启了 WithContourPermutation
+	MatchThreshold         float64           // SetMatchThreshold 设置的得分阈值，0 表示逐点判断
+	CurveFlattening        float64           // 开启 WithCurveFlattening 时展开曲线的精度（SetFlatness），否则为 0
+
+	Mapping Mapping
+}
+
+// savedMappingJSON 是映射文件的 JSON 格式，映射的键和值都是字符本身
+type savedMappingJSON struct {
+	Version          int           `json:"version"`
+	SpecialChecksum  string        `json:"special_sha256,omitempty"`
+	StandardChecksum string        `json:"standard_sha256,omitempty"`
+	Tolerance        fixed.Int26_6 `json:"tolerance,omitempty"`
+	CompareScale     fixed.Int26_6 `json:"compare_scale,omitempty"`
+
+	ExtraStandardChecksums map[string]string `json:"extra_standard_sha256,omitempty"`
+	FloatCoordinates       bool              `json:"float_coordinates,omitempty"`
+	FloatTolerance         float64           `json:"float_tolerance,omitempty"`
+	StartPointInvariant    bool              `json:"start_point_invariant,omitempty"`
+	ContourPermutation     bool              `json:"contour_permutation,omitempty"`
+	MatchThreshold         float64           `json:"match_threshold,omitempty"`
+	CurveFlattening        float64           `json:"curve_flattening,omitempty"`
+
+	Mapping map[string]string `json:"mapping"`
+}
+
+// Save 把映射以带版本号的 JSON 写入 w，不包含来源信息。需要记录字体校验和时使用 GlyphOutlineMapper.SaveMapping。
+// 映射中有代理项或超出 Unicode 范围的码位时返回错误，什么也不写
+func (m Mapping) Save(w io.Writer) error {
+	return saveMapping(w, SavedMapping{Mapping: m})
+}
+
+// SaveMapping 把由 g 生成的映射 m 写入 w，同时记录全部字体的校验和、比较容差以及影响比较结果的模式，
+// 之后可以用 SavedMapping.Matches 判断缓存的映射是否仍然适用
+func (g *GlyphOutlineMapper) SaveMapping(w io.Writer, m Mapping) error {
+	saved := g.savedConfig()
+	saved.Mapping = m
+	return saveMapping(w, saved)
+}
+
+// savedConfig 返回按 g 的字体和配置填好、没有映射的 SavedMapping
+func (g *GlyphOutlineMapper) savedConfig() SavedMapping {
+	saved := SavedMapping{
+		SpecialChecksum:     g.specialChecksum,
+		StandardChecksum:    g.standardChecksum,
+		Tolerance:           g.tolerance,
+		CompareScale:        g.scale,
+		FloatCoordinates:    g.floatCoordinates,
+		StartPointInvariant: g.startPointInvariant,
+		ContourPermutation:  g.contourPermutation,
+		MatchThreshold:      g.matchThreshold,
+	}
+	for _, f := range g.extraStandardFonts {
+		if saved.ExtraStandardChecksums == nil {
+			saved.ExtraStandardChecksums = map[string]string{}
+		}
+		saved.ExtraStandardChecksums[f.name] = f.checksum
+	}
+	if g.floatCoordinates {
+		saved.FloatTolerance = g.floatTolerance
+	}
+	if g.curveFlattening {
+		saved.CurveFlattening = g.flatness
+	}
+	return saved
+}
+
+func saveMapping(w io.Writer, saved SavedMapping) error {
+	file := savedMappingJSON{
+		Version:          mappingFileVersion,
+		SpecialChecksum:  saved.SpecialChecksum,
+		StandardChecksum: saved.StandardChecksum,
+		Tolerance:        saved.Tolerance,
+		CompareScale:     saved.CompareScale,
+
+		ExtraStandardChecksums: saved.ExtraStandardChecksums,
+		FloatCoordinates:       saved.FloatCoordinates,
+		FloatTolerance:         saved.FloatTolerance,
+		StartPointInvariant:    saved.StartPointInvariant,
+		ContourPermutation:     saved.ContourPermutation,
+		MatchThreshold:         saved.MatchThreshold,
+		CurveFlattening:        saved.CurveFlattening,
+
+		Mapping: make(map[string]string, len(saved.Mapping)),
+	}
+	for special, standard := range saved.Mapping {
+		// string(rune) 把代理项和越界的码位都写成 U+FFFD，读回时会互相覆盖
+		if !utf8.ValidRune(special) || !utf8.ValidRune(standard) {
+			return fmt.Errorf("mapping entry %U => %U is not a valid rune", special, standard)
+		}
+		file.Mapping[string(special)] = string(standard)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(file)
+}
+
+// LoadMapping 读取 Save 或 SaveMapping 写出的映射文件，不支持的版本或损坏的记录会返回错误
+func LoadMapping(r io.Reader) (*SavedMapping, error) {
+	var file savedMappingJSON
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("decode mapping failed: %w", err)
+	}
+	if file.Version != mappingFileVersion {
+		return nil, fmt.Errorf("unsupported mapping file version %d", file.Version)
+	}
+	saved := &SavedMapping{
+		Version:          file.Version,
+		SpecialChecksum:  file.SpecialChecksum,
+		StandardChecksum: file.StandardChecksum,
+		Tolerance:        file.Tolerance,
+		CompareScale:     file.CompareScale,
+
+		ExtraStandardChecksums: file.ExtraStandardChecksums,
+		FloatCoordinates:       file.FloatCoordinates,
+		FloatTolerance:         file.FloatTolerance,
+		StartPointInvariant:    file.StartPointInvariant,
+		ContourPermutation:     file.ContourPermutation,
+		MatchThreshold:         file.MatchThreshold,
+		CurveFlattening:        file.CurveFlattening,
+
+		Mapping: make(Mapping, len(file.Mapping)),
+	}
+	for special, standard := range file.Mapping {
+		s, sn := utf8.DecodeRuneInString(special)
+		t, tn := utf8.DecodeRuneInString(standard)
+		if sn == 0 || sn != len(special) || tn == 0 || tn != len(standard) {
+			return nil, fmt.Errorf("bad mapping entry %q => %q", special, standard)
+		}
+		saved.Mapping[s] = t
+	}
+	return saved, nil
+}
+
+// Matches 判断 s 是否是用与 g 相同的字体（包括 AddStandardFont 追加的字体）、比较容差和比较模式生成的，
+// 不匹配时应当重新计算映射
+func (s *SavedMapping) Matches(g *GlyphOutlineMapper) bool {
+	want := g.savedConfig()
+	return s.SpecialChecksum == want.SpecialChecksum && s.StandardChecksum == want.StandardChecksum &&
+		s.Tolerance == want.Tolerance && s.CompareScale == want.CompareScale &&
+		maps.Equal(s.ExtraStandardChecksums, want.ExtraStandardChecksums) &&
+		s.FloatCoordinates == want.FloatCoordinates && s.FloatTolerance == want.FloatTolerance &&
+		s.StartPointInvariant == want.StartPointInvariant && s.ContourPermutation == want.ContourPermutation &&
+		s.MatchThreshold == want.MatchThreshold && s.CurveFlattening == want.CurveFlattening
+}
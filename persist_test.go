@@ -0,0 +1,143 @@
+package mapper
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGlyphOutlineMapper_SaveMapping(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := mapper.Mapping(0xE000, 0xE000)
+
+	var buf bytes.Buffer
+	if err := mapper.SaveMapping(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := LoadMapping(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(saved.Mapping, m) || saved.Version != 1 || len(saved.SpecialChecksum) != 64 {
+		t.Errorf("round trip = %+v, want %v", saved, m)
+	}
+	if !saved.Matches(mapper) {
+		t.Error("saved mapping should match the mapper that produced it")
+	}
+
+	loose, err := NewGlyphOutlineMapper(special, standard, WithTolerance(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Matches(loose) {
+		t.Error("a different tolerance should not match")
+	}
+	other, err := NewGlyphOutlineMapper(encodeTestWOFF(special), standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Matches(other) {
+		t.Error("different special font data should not match")
+	}
+}
+
+func TestLoadMapping_Errors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (Mapping{0xE000: 'A'}).Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if saved, err := LoadMapping(&buf); err != nil || saved.Mapping[0xE000] != 'A' {
+		t.Fatalf("got %+v, %v", saved, err)
+	}
+
+	for _, input := range []string{
+		`{"version": 2, "mapping": {}}`,
+		`{"version": 1, "mapping": {"AB": "C"}}`,
+		`not json`,
+	} {
+		if _, err := LoadMapping(strings.NewReader(input)); err == nil {
+			t.Errorf("LoadMapping(%s) should fail", input)
+		}
+	}
+
+	// 两个代理项用 string(rune) 编码后都是 U+FFFD，不能写出
+	for _, m := range []Mapping{{0xD800: 'A', 0xDFFF: 'B'}, {0xE000: 0x110000}} {
+		buf.Reset()
+		if err := m.Save(&buf); err == nil || buf.Len() != 0 {
+			t.Errorf("Save(%v) = %v, wrote %d bytes, want an error and no output", m, err, buf.Len())
+		}
+	}
+}
+
+func TestSavedMapping_MatchesModes(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1})
+	extra := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 0, 500)}, advance: 800},
+	}, map[rune]rune{'B': 1})
+
+	newMapper := func(opts ...Option) *GlyphOutlineMapper {
+		g, err := NewGlyphOutlineMapper(special, standard, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return g
+	}
+	mapper := newMapper(WithFloatCoordinates(0.01), WithStartPointInvariance(), WithContourPermutation(), WithCurveFlattening())
+	mapper.SetMatchThreshold(0.5)
+	if err := mapper.AddStandardFont(extra, "extra"); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := mapper.SaveMapping(&buf, mapper.Mapping(0xE000, 0xE000)); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := LoadMapping(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.ExtraStandardChecksums["extra"]) != 64 || !saved.FloatCoordinates || saved.MatchThreshold != 0.5 || saved.CurveFlattening != defaultFlatness {
+		t.Errorf("saved config = %+v", saved)
+	}
+	if !saved.Matches(mapper) {
+		t.Error("saved mapping should match the mapper that produced it")
+	}
+
+	withoutExtra := newMapper(WithFloatCoordinates(0.01), WithStartPointInvariance(), WithContourPermutation(), WithCurveFlattening())
+	withoutExtra.SetMatchThreshold(0.5)
+	if saved.Matches(withoutExtra) {
+		t.Error("a mapper without the extra standard font should not match")
+	}
+	for name, opts := range map[string][]Option{
+		"float":       {WithStartPointInvariance(), WithContourPermutation(), WithCurveFlattening()},
+		"start point": {WithFloatCoordinates(0.01), WithContourPermutation(), WithCurveFlattening()},
+		"permutation": {WithFloatCoordinates(0.01), WithStartPointInvariance(), WithCurveFlattening()},
+		"flattening":  {WithFloatCoordinates(0.01), WithStartPointInvariance(), WithContourPermutation()},
+		"threshold":   {WithFloatCoordinates(0.01), WithStartPointInvariance(), WithContourPermutation(), WithCurveFlattening()},
+	} {
+		g := newMapper(opts...)
+		if name != "threshold" {
+			g.SetMatchThreshold(0.5)
+		}
+		if err := g.AddStandardFont(extra, "extra"); err != nil {
+			t.Fatal(err)
+		}
+		if saved.Matches(g) {
+			t.Errorf("%s: a mapper with a different mode should not match", name)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package mapper
+
+import (
+	"math"
+	"math/bits"
+	"slices"
+)
+
+// hashBands 是感知哈希索引把 64 位哈希拆成的段数。两个哈希的汉明距离小于段数时，
+// 至少有一段完全相同，只需要查找这些段相同的候选
+const hashBands = 4
+
+// WithPerceptualHash 改为按字形位图的感知哈希（pHash）比较字形：两个字形的哈希最多相差 maxDistance 位时认为一致。
+// 哈希在字形自身的边界框内计算，对轻微的变形、曲线编码方式和位置变化都不敏感，
+// 但形状相同、只是位置或大小不同的字形无法区分，建议只用于初步筛选或配合 MappingDetailed 复核。
+// maxDistance 小于 4 时标准字体的哈希会被建立索引，每个字符只需要比较少数候选，而不是扫描全部字形
+func WithPerceptualHash(maxDistance int) Option {
+	return func(g *GlyphOutlineMapper) {
+		g.perceptualHash = true
+		g.hashDistance = max(maxDistance, 0)
+	}
+}
+
+// PerceptualHashMatcher 返回与 WithPerceptualHash 相同的比较：两个字形的感知哈希最多相差 maxDistance 位时认为一致，
+// 便于与其他 matcher 组合使用。每次比较都会重新渲染两个字形，比 WithPerceptualHash 慢
+func PerceptualHashMatcher(maxDistance int) GlyphMatcher {
+	return GlyphMatcherFunc(func(special, standard GlyphData) (bool, float64) {
+		a := glyphPHash(&outline{points: special.Points, ends: special.Ends})
+		b := glyphPHash(&outline{points: standard.Points, ends: standard.Ends})
+		distance := bits.OnesCount64(a ^ b)
+		if distance > maxDistance {
+			return false, math.Inf(1)
+		}
+		return true, relativeDeviation(float64(distance), 1, float64(maxDistance))
+	})
+}
+
+// phashSize 是计算感知哈希时渲染的位图边长
+const phashSize = 32
+
+// phashCos[u][x] 是 32 点 DCT-II 的第 u 个基函数在 x 处的值，只需要最低的 8 个频率
+var phashCos = func() (c [8][phashSize]float64) {
+	for u := range 8 {
+		for x := range phashSize {
+			c[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / (2 * phashSize))
+		}
+	}
+	return c
+}()
+
+// glyphPHash 计算字形的 64 位感知哈希：把字形渲染为 32×32 的位图，取二维 DCT 最低的 8×8 个频率，
+// 大于这些系数（不含直流分量）中位数的记为 1
+func glyphPHash(o *outline) uint64 {
+	if len(o.points) == 0 {
+		return 0
+	}
+	glyph := GlyphData{Points: o.points, Ends: o.ends}
+	bitmap := rasterizeGlyph(glyph, glyphBounds(glyph, [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}), phashSize)
+	// DCT 可以分离，先对每一行、再对每一列做一维变换
+	var rows [phashSize][8]float64
+	for y := range phashSize {
+		for u := range 8 {
+			for x := range phashSize {
+				rows[y][u] += float64(bitmap.Pix[y*bitmap.Stride+x]) * phashCos[u][x]
+			}
+		}
+	}
+	var coefs [64]float64
+	for v := range 8 {
+		for u := range 8 {
+			for y := range phashSize {
+				coefs[v*8+u] += rows[y][u] * phashCos[v][y]
+			}
+		}
+	}
+	sorted := slices.Clone(coefs[1:])
+	slices.Sort(sorted)
+	// 填满边界框的字形除直流分量外的系数都是 0，加上与直流分量成比例的余量，避免浮点误差决定这些位
+	threshold := sorted[len(sorted)/2] + math.Abs(coefs[0])*1e-9
+	var hash uint64
+	for _, c := range coefs {
+		hash <<= 1
+		if c > threshold {
+			hash |= 1
+		}
+	}
+	return hash
+}
+
+// compareHashes 比较两个字形的感知哈希
+func (g *GlyphOutlineMapper) compareHashes(a, b *cachedGlyph) (bool, float64) {
+	distance := bits.OnesCount64(a.hash ^ b.hash)
+	if distance > g.hashDistance {
+		return false, math.Inf(1)
+	}
+	return true, relativeDeviation(float64(distance), 1, float64(g.hashDistance))
+}
+
+// indexHashes 按感知哈希的每一段建立标准字形的索引
+func (c *standardCache) indexHashes() {
+	c.hashIndex = make([]map[uint16][]int, hashBands)
+	for band := range c.hashIndex {
+		c.hashIndex[band] = map[uint16][]int{}
+	}
+	for i, glyph := range c.glyphs {
+		for band := range hashBands {
+			key := uint16(glyph.hash >> (16 * band))
+			c.hashIndex[band][key] = append(c.hashIndex[band][key], i)
+		}
+	}
+}
+
+// hashCandidates 返回可能与 special 匹配的标准字形，顺序与 glyphs 相同。没有建立哈希索引（或 WithHashLSH 的近似索引）时返回全部字形
+func (c *standardCache) hashCandidates(special *cachedGlyph) []*cachedGlyph {
+	var indexes []int
+	switch {
+	case c.hashIndex != nil:
+		for band := range hashBands {
+			indexes = append(indexes, c.hashIndex[band][uint16(special.hash>>(16*band))]...)
+		}
+		slices.Sort(indexes)
+		indexes = slices.Compact(indexes)
+	case c.lsh != nil:
+		indexes = c.lsh.lshCandidates(special.hash)
+	default:
+		return c.glyphs
+	}
+	candidates := make([]*cachedGlyph, len(indexes))
+	for i, index := range indexes {
+		candidates[i] = c.glyphs[index]
+	}
+	return candidates
+}
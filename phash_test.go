@@ -0,0 +1,64 @@
+package mapper
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithPerceptualHash(t *testing.T) {
+	// 特殊字形是稍微放大、平移并用两个三角形重新拼出的正方形
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{
+			{{150, 150, false}, {150, 670, false}, {670, 670, false}},
+			{{150, 150, false}, {670, 670, false}, {670, 150, false}},
+		}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{lShape()}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'L': 2, 'O': 3})
+
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithPerceptualHash(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config := mapper.Config(); !config.PerceptualHash || config.HashDistance != 2 {
+		t.Errorf("Config() = %+v", config)
+	}
+	if _, standardRune, ok := mapper.MappingRune(0xE000); !ok || standardRune != 'O' {
+		t.Errorf("got %q (ok=%v), want 'O'", standardRune, ok)
+	}
+
+	// 哈希索引只返回至少有一段哈希相同的候选
+	cache, err := mapper.standardGlyphs(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	glyph, loadErr := mapper.loadCachedGlyph(mapper.specialFont, "special", 0xE000)
+	if loadErr != nil {
+		t.Fatal(loadErr)
+	}
+	candidates := cache.hashCandidates(glyph)
+	if len(candidates) == 0 || len(candidates) == len(cache.glyphs) {
+		t.Errorf("got %d candidates out of %d glyphs", len(candidates), len(cache.glyphs))
+	}
+}
+
+func TestGlyphPHash(t *testing.T) {
+	o := &outline{}
+	if got := glyphPHash(o); got != 0 {
+		t.Errorf("empty glyph hash = %x, want 0", got)
+	}
+	square := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{square(100, 100, 500)}, advance: 800}}, map[rune]rune{'A': 1})
+	triangle := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{triangle(100, 100, 500)}, advance: 800}}, map[rune]rune{'A': 1})
+	mapper, err := NewGlyphOutlineMapper(square, triangle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := mapper.loadCachedGlyph(mapper.specialFont, "special", 'A')
+	b, _ := mapper.loadCachedGlyph(mapper.standardFont, "standard", 'A')
+	if glyphPHash(a.outline) == glyphPHash(b.outline) {
+		t.Error("square and triangle share a hash")
+	}
+}
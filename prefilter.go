@@ -0,0 +1,69 @@
+package mapper
+
+import (
+	"math"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// outlineBounds 返回轮廓所有点的边界框 minX、minY、maxX、maxY，没有点时返回零值
+func outlineBounds(o *outline) [4]fixed.Int26_6 {
+	if len(o.points) == 0 {
+		return [4]fixed.Int26_6{}
+	}
+	p := o.points[0]
+	b := [4]fixed.Int26_6{p.X, p.Y, p.X, p.Y}
+	for _, p := range o.points[1:] {
+		b = [4]fixed.Int26_6{min(b[0], p.X), min(b[1], p.Y), max(b[2], p.X), max(b[3], p.Y)}
+	}
+	return b
+}
+
+// compareOutlinePipeline 逐点比较两个已加载的字形，比较之前依次用代价从低到高的条件排除明显不同的候选：
+// 轮廓数量 → 点数 → 边界框 → 轮廓端点和逐点坐标。每个点都在容差之内时边界框的四条边也一定在容差之内，
+// 所以边界框只会排除逐点比较本来就会拒绝的候选。按顺序比较失败的多轮廓字形再按 compareTiedOutlines 对应一次，
+// 仍然失败并且开启了 WithContourPermutation 时最后按任意顺序对应。
+// 更早的哈希阶段见 WithRawGlyphMatch 和 WithOutlineHash。偏差相对于 tolerance，通常是 g.tolerance
+func (g *GlyphOutlineMapper) compareOutlinePipeline(special, standard *cachedGlyph, tolerance fixed.Int26_6) (bool, float64) {
+	a, b := special.outline, standard.outline
+	if len(a.ends) != len(b.ends) || len(a.points) != len(b.points) {
+		return false, math.Inf(1)
+	}
+	for i := range special.bounds {
+		if abs(special.bounds[i]-standard.bounds[i]) > tolerance {
+			return false, math.Inf(1)
+		}
+	}
+	return g.pairOutlines(a, b, tolerance, false)
+}
+
+// pairOutlines 按配置的对应方式逐点比较两个轮廓，偏差相对于 tolerance：先按加载时排好的顺序比较
+// （开启 WithStartPointInvariance 时每个轮廓可以循环移位），多轮廓字形再按 compareTiedOutlines 对应，
+// 开启了 WithContourPermutation 时最后按任意顺序对应。exhaustive 为 false 时第一个成功的对应方式就是结果，
+// 为 true 时尝试全部对应方式，取偏差最小的一个
+func (g *GlyphOutlineMapper) pairOutlines(a, b *outline, tolerance fixed.Int26_6, exhaustive bool) (bool, float64) {
+	var ok bool
+	var deviation float64
+	if g.startPointInvariant {
+		ok, deviation = compareCyclicOutlines(a, b, tolerance, g.ignorePointFlags)
+	} else {
+		ok, deviation = compareGlyphOutlines(a, b, tolerance, g.ignorePointFlags)
+	}
+	if ok && !exhaustive || len(a.ends) < 2 {
+		return ok, deviation
+	}
+	better := func(candidateOK bool, candidate float64) {
+		if candidateOK && (!ok || candidate < deviation) {
+			ok, deviation = true, candidate
+		}
+	}
+	// 加载时按精确的边界排序，边界只差容差以内的轮廓在两个字体中可能排成不同的顺序，
+	// 这时只在这些轮廓之间重新对应一次
+	better(compareTiedOutlines(a, b, tolerance, g.tolerance, g.ignorePointFlags, g.startPointInvariant))
+	if ok && !exhaustive || !g.contourPermutation {
+		return ok, deviation
+	}
+	// 贪心的对应可能拒绝按顺序对应能够接受的字形，所以只在前面的比较都失败之后使用
+	better(comparePermutedOutlines(a, b, tolerance, g.ignorePointFlags, g.startPointInvariant))
+	return ok, deviation
+}
@@ -0,0 +1,39 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestCompareOutlinePipeline(t *testing.T) {
+	g := &GlyphOutlineMapper{tolerance: 10}
+	glyph := func(points ...truetype.Point) *cachedGlyph {
+		o := &outline{points: points, ends: []int{len(points)}}
+		return &cachedGlyph{outline: o, bounds: outlineBounds(o)}
+	}
+	base := glyph(truetype.Point{X: 0, Y: 0}, truetype.Point{X: 100, Y: 0}, truetype.Point{X: 100, Y: 100})
+	if got := base.bounds; got != [4]fixed.Int26_6{0, 0, 100, 100} {
+		t.Errorf("bounds = %v", got)
+	}
+
+	tests := []struct {
+		name     string
+		standard *cachedGlyph
+	}{
+		{"identical", glyph(truetype.Point{X: 0, Y: 0}, truetype.Point{X: 100, Y: 0}, truetype.Point{X: 100, Y: 100})},
+		{"within tolerance", glyph(truetype.Point{X: 5, Y: 0}, truetype.Point{X: 100, Y: 0}, truetype.Point{X: 100, Y: 108})},
+		{"bounds differ", glyph(truetype.Point{X: 0, Y: 0}, truetype.Point{X: 100, Y: 0}, truetype.Point{X: 100, Y: 150})},
+		{"point count differs", glyph(truetype.Point{X: 0, Y: 0}, truetype.Point{X: 100, Y: 100})},
+		// 边界框相同，但点的顺序不同
+		{"same bounds", glyph(truetype.Point{X: 100, Y: 0}, truetype.Point{X: 0, Y: 0}, truetype.Point{X: 100, Y: 100})},
+	}
+	for _, tt := range tests {
+		gotOK, gotDeviation := g.compareOutlinePipeline(base, tt.standard, g.tolerance)
+		wantOK, wantDeviation := compareGlyphOutlines(base.outline, tt.standard.outline, g.tolerance, false)
+		if gotOK != wantOK || gotDeviation != wantDeviation {
+			t.Errorf("%s: pipeline = (%v, %v), want (%v, %v)", tt.name, gotOK, gotDeviation, wantOK, wantDeviation)
+		}
+	}
+}
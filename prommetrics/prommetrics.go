@@ -0,0 +1,129 @@
+// Package prommetrics 把 mapper.Metrics 导出为 Prometheus 的计数器和直方图。
+// 直接输出 Prometheus 的文本格式（0.0.4），核心包和本包都不依赖 client_golang：
+// 把 *Metrics 注册为 HTTP 处理器即可被 Prometheus 抓取，例如
+//
+//	m := prommetrics.New("font_mapper", nil)
+//	g, err := mapper.NewGlyphOutlineMapper(special, standard, mapper.WithMetrics(m))
+//	http.Handle("/metrics", m)
+package prommetrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	mapper "github.com/bestnite/font-mapper"
+)
+
+// DefaultBuckets 是比较耗时直方图默认的桶上界，单位为秒，覆盖单个字符从 0.1 毫秒到 1 秒的比较耗时
+var DefaultBuckets = []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}
+
+// Metrics 实现 mapper.Metrics 和 http.Handler，可以由多个 mapper 共享
+type Metrics struct {
+	namespace string
+	buckets   []float64
+
+	compared, cacheHits, matches, errors atomic.Int64
+
+	mu     sync.Mutex
+	counts []uint64 // 落在每个桶中的次数，最后一个是 +Inf
+	count  uint64
+	sum    float64
+}
+
+var _ mapper.Metrics = (*Metrics)(nil)
+
+// New 创建 Metrics，指标名以 namespace 加下划线开头，namespace 为空时不加前缀。
+// buckets 是比较耗时直方图的桶上界（秒），为空时使用 DefaultBuckets
+func New(namespace string, buckets []float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+	buckets = slices.Compact(buckets)
+	return &Metrics{namespace: namespace, buckets: buckets, counts: make([]uint64, len(buckets)+1)}
+}
+
+func (m *Metrics) AddCompared(n int) { m.compared.Add(int64(n)) }
+func (m *Metrics) AddCacheHit()      { m.cacheHits.Add(1) }
+func (m *Metrics) AddMatch()         { m.matches.Add(1) }
+func (m *Metrics) AddError()         { m.errors.Add(1) }
+
+func (m *Metrics) ObserveCompareLatency(d time.Duration) {
+	seconds := d.Seconds()
+	i, _ := slices.BinarySearch(m.buckets, seconds)
+	m.mu.Lock()
+	m.counts[i]++
+	m.count++
+	m.sum += seconds
+	m.mu.Unlock()
+}
+
+// ServeHTTP 以 Prometheus 的文本格式输出全部指标
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// WriteTo 以 Prometheus 的文本格式把全部指标写入 w
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	counting := &countingWriter{w: w}
+	b := bufio.NewWriter(counting)
+	m.writeCounter(b, "glyphs_compared_total", "Standard glyphs compared against special glyphs.", m.compared.Load())
+	m.writeCounter(b, "cache_hits_total", "Decoded runes served from the rune cache.", m.cacheHits.Load())
+	m.writeCounter(b, "matches_total", "Special runes that found a match.", m.matches.Load())
+	m.writeCounter(b, "errors_total", "Glyphs that failed to load or timed out.", m.errors.Load())
+
+	m.mu.Lock()
+	counts, count, sum := slices.Clone(m.counts), m.count, m.sum
+	m.mu.Unlock()
+	name := m.name("compare_latency_seconds")
+	fmt.Fprintf(b, "# HELP %s Time spent comparing one special rune with its candidates.\n# TYPE %s histogram\n", name, name)
+	var cumulative uint64
+	for i, upper := range m.buckets {
+		cumulative += counts[i]
+		fmt.Fprintf(b, "%s_bucket{le=%q} %d\n", name, formatFloat(upper), cumulative)
+	}
+	fmt.Fprintf(b, "%s_bucket{le=\"+Inf\"} %d\n%s_sum %s\n%s_count %d\n", name, count, name, formatFloat(sum), name, count)
+	err := b.Flush()
+	return counting.n, err
+}
+
+func (m *Metrics) writeCounter(w io.Writer, suffix, help string, value int64) {
+	name := m.name(suffix)
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, help, name, name, value)
+}
+
+func (m *Metrics) name(suffix string) string {
+	if m.namespace == "" {
+		return suffix
+	}
+	return m.namespace + "_" + suffix
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// countingWriter 记录写入的字节数，用于 WriteTo 的返回值
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,50 @@
+package prommetrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	m := New("font_mapper", []float64{0.01, 0.001})
+	m.AddCompared(3)
+	m.AddCompared(2)
+	m.AddCacheHit()
+	m.AddMatch()
+	m.AddError()
+	m.ObserveCompareLatency(500 * time.Microsecond)
+	m.ObserveCompareLatency(5 * time.Millisecond)
+	m.ObserveCompareLatency(time.Second)
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", got)
+	}
+	body := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE font_mapper_glyphs_compared_total counter",
+		"font_mapper_glyphs_compared_total 5",
+		"font_mapper_cache_hits_total 1",
+		"font_mapper_matches_total 1",
+		"font_mapper_errors_total 1",
+		"# TYPE font_mapper_compare_latency_seconds histogram",
+		`font_mapper_compare_latency_seconds_bucket{le="0.001"} 1`,
+		`font_mapper_compare_latency_seconds_bucket{le="0.01"} 2`,
+		`font_mapper_compare_latency_seconds_bucket{le="+Inf"} 3`,
+		"font_mapper_compare_latency_seconds_sum 1.0055",
+		"font_mapper_compare_latency_seconds_count 3",
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("missing %q in\n%s", line, body)
+		}
+	}
+
+	var b strings.Builder
+	n, err := m.WriteTo(&b)
+	if err != nil || n != int64(b.Len()) || b.String() != body {
+		t.Errorf("WriteTo = %d, %v; output differs from ServeHTTP", n, err)
+	}
+}
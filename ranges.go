@@ -0,0 +1,62 @@
+package mapper
+
+import (
+	"context"
+	"slices"
+)
+
+// 常用的字符范围，可以传给 MappingRanges、SetCandidateRanges 等
+var (
+	// PrivateUseArea 是基本多文种平面的私有使用区，大多数混淆字体使用这个范围
+	PrivateUseArea = RuneRange{Start: 0xE000, End: 0xF8FF}
+	// SupplementaryPUAA 是第 15 平面的补充私有使用区 A
+	SupplementaryPUAA = RuneRange{Start: 0xF0000, End: 0xFFFFD}
+	// SupplementaryPUAB 是第 16 平面的补充私有使用区 B
+	SupplementaryPUAB = RuneRange{Start: 0x100000, End: 0x10FFFD}
+	// CJKExtensionB 是第 2 平面的 CJK 统一汉字扩展 B 区
+	CJKExtensionB = RuneRange{Start: 0x20000, End: 0x2A6DF}
+)
+
+// 常用的候选字符集合，传给 SetCandidateRanges 后只在这些字符中查找，多个集合可以用 slices.Concat 组合。
+// 适用于价格、数字等只会出现少数字符的混淆场景，比扫描整个标准字体快，也不容易误匹配到形状相同的其他字符
+var (
+	// CandidatesDigits 是 ASCII 数字和全角数字
+	CandidatesDigits = []RuneRange{{'0', '9'}, {0xFF10, 0xFF19}}
+	// CandidatesLatin 是基本拉丁字母、带变音符号的拉丁字母以及全角拉丁字母
+	CandidatesLatin = []RuneRange{{'A', 'Z'}, {'a', 'z'}, {0xC0, 0x24F}, {0xFF21, 0xFF3A}, {0xFF41, 0xFF5A}}
+	// CandidatesPunctuation 是 ASCII 标点、通用标点、CJK 标点以及全角标点
+	CandidatesPunctuation = []RuneRange{
+		{0x21, 0x2F}, {0x3A, 0x40}, {0x5B, 0x60}, {0x7B, 0x7E},
+		{0x2000, 0x206F}, {0x3000, 0x303F},
+		{0xFF01, 0xFF0F}, {0xFF1A, 0xFF20}, {0xFF3B, 0xFF40}, {0xFF5B, 0xFF65},
+	}
+	// CandidatesKana 是平假名、片假名及其扩展，以及半角片假名
+	CandidatesKana = []RuneRange{{0x3040, 0x309F}, {0x30A0, 0x30FF}, {0x31F0, 0x31FF}, {0xFF66, 0xFF9F}}
+	// CandidatesHangul 是谚文音节、谚文字母和兼容字母
+	CandidatesHangul = []RuneRange{{0xAC00, 0xD7A3}, {0x1100, 0x11FF}, {0x3130, 0x318F}}
+	// CandidatesCJK 是 CJK 统一汉字基本区
+	CandidatesCJK = []RuneRange{{0x4E00, 0x9FFF}}
+)
+
+// isPrivateUse 判断字符是否在某个私有使用区内
+func isPrivateUse(r rune) bool {
+	for _, rr := range []RuneRange{PrivateUseArea, SupplementaryPUAA, SupplementaryPUAB} {
+		if r >= rr.Start && r <= rr.End {
+			return true
+		}
+	}
+	return false
+}
+
+// MappingRanges 与 Mapping 相同，但依次映射多个范围，例如同时映射三个私有使用区：
+//
+//	m := mapper.MappingRanges(PrivateUseArea, SupplementaryPUAA, SupplementaryPUAB)
+//
+// 只会比较特殊字体 cmap 中编码的字符，不会逐个遍历补充平面中的大片空白
+func (g *GlyphOutlineMapper) MappingRanges(ranges ...RuneRange) Mapping {
+	runes := slices.DeleteFunc(g.SpecialRunes(), func(r rune) bool {
+		return !slices.ContainsFunc(ranges, func(rr RuneRange) bool { return r >= rr.Start && r <= rr.End })
+	})
+	results, _, _, _ := g.mappingRunes(context.Background(), slices.Values(runes), len(runes), false, nil, nil)
+	return resultsMap(results)
+}
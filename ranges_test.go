@@ -0,0 +1,65 @@
+package mapper
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGlyphOutlineMapper_MappingRanges(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xF0000: 1, 0x10FFFD: 2})
+	// 标准字符位于第 2 平面
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{0x20000: 1, 0x2A6DF: 2})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := mapper.MappingRanges(SupplementaryPUAA, SupplementaryPUAB)
+	want := Mapping{0xF0000: 0x20000, 0x10FFFD: 0x2A6DF}
+	if len(got) != len(want) || got[0xF0000] != want[0xF0000] || got[0x10FFFD] != want[0x10FFFD] {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if _, standardRune, ok := mapper.MappingRune(0x10FFFD); !ok || standardRune != 0x2A6DF {
+		t.Errorf("MappingRune(U+10FFFD) = %U (ok=%v), want U+2A6DF", standardRune, ok)
+	}
+}
+
+func TestCandidatePresets(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	// 形状相同的字符出现在多个集合中
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'!': 1, '7': 1, 'A': 1, 0x30A2: 1, 0xAC00: 1, 0x4E00: 1})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		ranges []RuneRange
+		want   rune
+	}{
+		{"digits", CandidatesDigits, '7'},
+		{"latin", CandidatesLatin, 'A'},
+		{"punctuation", CandidatesPunctuation, '!'},
+		{"kana", CandidatesKana, 0x30A2},
+		{"hangul", CandidatesHangul, 0xAC00},
+		{"cjk", CandidatesCJK, 0x4E00},
+		{"combined", slices.Concat(CandidatesLatin, CandidatesDigits), 'A'},
+	}
+	for _, tt := range tests {
+		mapper.SetCandidateRanges(tt.ranges)
+		if _, got, ok := mapper.MappingRune(0xE000); !ok || got != tt.want {
+			t.Errorf("%s: got %U (ok=%v), want %U", tt.name, got, ok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package mapper
+
+import (
+	"image"
+	"math"
+
+	"golang.org/x/image/vector"
+)
+
+// RasterMatcher 返回按像素比较的 GlyphMatcher：两个字形以相同的缩放和偏移渲染到 size×size 的位图上
+// （两者合并后的边界框较长的一边占满位图），再统计覆盖情况不同的像素。
+// budget 是允许不同的像素占任意一个字形覆盖的像素的比例，例如 0.02；偏差是实际比例除以 budget。
+// 与逐点比较不同，它不关心轮廓由哪些点和曲线组成，混淆时重新编码了曲线的字形同样可以匹配，
+// 代价是每次比较都要渲染两张位图。通过 WithMatchers(RasterMatcher(64, 0.02)) 使用
+func RasterMatcher(size int, budget float64) GlyphMatcher {
+	size = max(size, 1)
+	return GlyphMatcherFunc(func(special, standard GlyphData) (bool, float64) {
+		if len(special.Points) == 0 || len(standard.Points) == 0 {
+			if len(special.Points) != len(standard.Points) {
+				return false, math.Inf(1)
+			}
+			return true, 0
+		}
+		a, b := rasterizePair(special, standard, size)
+		covered, mismatched := 0, 0
+		for i := range a.Pix {
+			inA, inB := a.Pix[i] >= 0x80, b.Pix[i] >= 0x80
+			if inA || inB {
+				covered++
+			}
+			if inA != inB {
+				mismatched++
+			}
+		}
+		if covered == 0 {
+			return true, 0
+		}
+		ratio := float64(mismatched) / float64(covered)
+		if ratio > budget {
+			return false, math.Inf(1)
+		}
+		return true, relativeDeviation(ratio, 1, budget)
+	})
+}
+
+// IoUMatcher 返回按填充后位图的交并比（IoU）比较的 GlyphMatcher，两个字形的渲染方式与 RasterMatcher 相同。
+// 交集和并集按每个像素的覆盖率累加，而不是先二值化，边缘抗锯齿的细微差别只会让 IoU 略微下降。
+// IoU 不低于 threshold（例如 0.9）时认为一致，偏差的换算使 MappingResult.Score 恰好等于 IoU
+func IoUMatcher(size int, threshold float64) GlyphMatcher {
+	size = max(size, 1)
+	return GlyphMatcherFunc(func(special, standard GlyphData) (bool, float64) {
+		if len(special.Points) == 0 || len(standard.Points) == 0 {
+			if len(special.Points) != len(standard.Points) {
+				return false, math.Inf(1)
+			}
+			return true, 0
+		}
+		a, b := rasterizePair(special, standard, size)
+		var intersection, union int
+		for i := range a.Pix {
+			intersection += int(min(a.Pix[i], b.Pix[i]))
+			union += int(max(a.Pix[i], b.Pix[i]))
+		}
+		if union == 0 {
+			return true, 0
+		}
+		iou := float64(intersection) / float64(union)
+		if iou < threshold || iou == 0 {
+			return false, math.Inf(1)
+		}
+		return true, 1/iou - 1
+	})
+}
+
+// rasterizePair 以相同的缩放和偏移渲染两个字形，两者合并后的边界框较长的一边占满 size×size 的位图
+func rasterizePair(a, b GlyphData, size int) (*image.Alpha, *image.Alpha) {
+	bounds := glyphBounds(a, glyphBounds(b, [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}))
+	return rasterizeGlyph(a, bounds, size), rasterizeGlyph(b, bounds, size)
+}
+
+// glyphBounds 把字形的边界并入 bounds（minX、minY、maxX、maxY）
+func glyphBounds(glyph GlyphData, bounds [4]float64) [4]float64 {
+	for _, p := range glyph.Points {
+		x, y := float64(p.X), float64(p.Y)
+		bounds = [4]float64{min(bounds[0], x), min(bounds[1], y), max(bounds[2], x), max(bounds[3], y)}
+	}
+	return bounds
+}
+
+// rasterizeGlyph 把 bounds 范围内的字形渲染为 size×size 的覆盖率位图，y 轴翻转为向下
+func rasterizeGlyph(glyph GlyphData, bounds [4]float64, size int) *image.Alpha {
+	extent := max(bounds[2]-bounds[0], bounds[3]-bounds[1], 1)
+	scale := float64(size) / extent
+	transform := func(v vec) (float32, float32) {
+		return float32((v.x - bounds[0]) * scale), float32(float64(size) - (v.y-bounds[1])*scale)
+	}
+	z := vector.NewRasterizer(size, size)
+	start := 0
+	for _, end := range glyph.Ends {
+		// 展开精度取四分之一像素
+		poly := flattenContour(glyph.Points[start:end], extent/float64(size)/4)
+		start = end
+		if len(poly) < 2 {
+			continue
+		}
+		z.MoveTo(transform(poly[0]))
+		for _, v := range poly[1:] {
+			z.LineTo(transform(v))
+		}
+		z.ClosePath()
+	}
+	dst := image.NewAlpha(image.Rect(0, 0, size, size))
+	z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+	return dst
+}
@@ -0,0 +1,72 @@
+package mapper
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRasterMatcher(t *testing.T) {
+	// 特殊字形用两个三角形拼出正方形，点的结构与标准字形完全不同，逐点比较无法匹配
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{
+			{{100, 100, false}, {100, 600, false}, {600, 600, false}},
+			{{100, 100, false}, {600, 600, false}, {600, 100, false}},
+		}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{{{100, 100, false}, {100, 350, false}, {100, 600, false}, {600, 600, false}, {600, 100, false}}}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+
+	plain, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, standardRune, ok := plain.MappingRune(0xE000); ok {
+		t.Errorf("re-encoded glyph matched %q with point comparison", standardRune)
+	}
+
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithMatchers(RasterMatcher(64, 0.02)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, ok := mapper.MappingRuneResult(0xE000)
+	if !ok || result.Standard != 'B' {
+		t.Fatalf("got %+v (ok=%v), want 'B'", result, ok)
+	}
+	if result.Score <= 0.5 {
+		t.Errorf("score %v, want above 0.5", result.Score)
+	}
+}
+
+func TestIoUMatcher(t *testing.T) {
+	// 特殊字形比标准字形宽 4%，逐点比较和严格的像素比较都无法匹配
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{{{100, 100, false}, {100, 600, false}, {620, 600, false}, {620, 100, false}}}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithMatchers(IoUMatcher(64, 0.9)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, ok := mapper.MappingRuneResult(0xE000)
+	if !ok || result.Standard != 'B' {
+		t.Fatalf("got %+v (ok=%v), want 'B'", result, ok)
+	}
+	// 面积之比 500/520
+	if want := 500.0 / 520; math.Abs(result.Score-want) > 0.01 {
+		t.Errorf("score %v, want about %v", result.Score, want)
+	}
+
+	strict, err := NewGlyphOutlineMapper(special, standard, WithMatchers(IoUMatcher(64, 0.99)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, standardRune, ok := strict.MappingRune(0xE000); ok {
+		t.Errorf("matched %q above the IoU threshold", standardRune)
+	}
+}
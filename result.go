@@ -0,0 +1,92 @@
+package mapper
+
+import (
+	"context"
+	"slices"
+)
+
+// MappingResult 是单个特殊字符的映射结果
+type MappingResult struct {
+	Special       rune           // 特殊字体中的字符
+	Standard      rune           // 轮廓一致的标准字符
+	StandardFont  string         // 匹配到的标准字体，"standard" 或 AddStandardFont 时指定的名字
+	Score         float64        // 匹配得分，定义与 GlyphSimilarity 相同
+	Ambiguous     bool           // 是否有不止一个标准字符在容差之内，只有 MappingDetailed、MappingWithStats 和 MappingRuneResult 会检测
+	LowConfidence bool           // 没有候选在容差之内，这是 WithNearestFallback 返回的最接近的候选，应当人工复核
+	Transform     GlyphTransform // 匹配时对特殊字形施加的变换，见 WithTransforms
+	Alternatives  []Candidate    // 选中的字符之外得分最高的候选，按得分从高到低排列，见 WithAlternatives
+}
+
+// newMappingResult 根据比较得到的偏差生成带得分的映射结果
+func newMappingResult(special, standard *cachedGlyph, deviation float64) MappingResult {
+	return MappingResult{Special: special.r, Standard: standard.r, StandardFont: standard.font, Score: similarityScore(deviation)}
+}
+
+// similarityScore 把相对于容差的偏差换算为 0~1 的得分：完全一致为 1，
+// 平均偏差恰好等于容差时为 0.5，无法匹配时为 0
+func similarityScore(deviation float64) float64 {
+	return 1 / (1 + deviation)
+}
+
+// SetMatchThreshold 改为按连续的得分判断是否匹配：候选与特殊字形的得分（定义与 MappingResult.Score 相同）
+// 不低于 threshold 时认为一致，不再要求每个点都在容差之内。0.5 相当于平均偏差恰好等于容差，
+// 调高可以减少误匹配，调低可以找回轻微变形的字形，便于按字体调整准确率和召回率。小于等于 0 时恢复默认的逐点判断。
+// 偏差按与逐点判断相同的方式对应轮廓和起点（WithStartPointInvariance、WithContourPermutation），取偏差最小的对应
+func (g *GlyphOutlineMapper) SetMatchThreshold(threshold float64) {
+	g.matchThreshold = max(threshold, 0)
+	// 缓存的解码结果依赖匹配的判断
+	g.resetCache()
+}
+
+// MappingRuneResult 与 MappingRune 相同，但返回带得分的结果。配合 WithMatchStrategy(BestMatch)
+// 可以得到偏差最小的候选及其得分，并检测是否有其他候选也在容差之内
+func (g *GlyphOutlineMapper) MappingRuneResult(unicode rune) (MappingResult, bool) {
+	result, ok, _, _ := g.mappingRune(context.Background(), unicode, true)
+	return result, ok
+}
+
+// MappingDetailed 与 Mapping 相同，但返回带得分的结果，按特殊字符升序排列。
+// 为了检测歧义，找到匹配后会继续扫描直到遇到第二个匹配的候选，结果可以通过 AmbiguousRunes 读取
+func (g *GlyphOutlineMapper) MappingDetailed(start, end rune) []MappingResult {
+	results, _, _, _ := g.mappingDetailed(context.Background(), start, end, true, nil)
+	return results
+}
+
+// recordAmbiguous 记录 results 中有歧义的特殊字符，results 按特殊字符升序排列
+func (g *GlyphOutlineMapper) recordAmbiguous(results []MappingResult) {
+	var ambiguous []rune
+	for _, result := range results {
+		if result.Ambiguous {
+			ambiguous = append(ambiguous, result.Special)
+		}
+	}
+	g.ambiguousMu.Lock()
+	g.ambiguous = ambiguous
+	g.ambiguousMu.Unlock()
+}
+
+// AmbiguousRunes 返回最近一次 MappingDetailed 或 MappingWithStats 中有多个标准字符在容差之内的特殊字符，按码位升序排列。
+// 这些字符最可能映射错误，适合人工复核
+func (g *GlyphOutlineMapper) AmbiguousRunes() []rune {
+	g.ambiguousMu.Lock()
+	defer g.ambiguousMu.Unlock()
+	return slices.Clone(g.ambiguous)
+}
+
+// GlyphSimilarity 返回两个字符字形的相似度得分（0~1），与 MappingResult.Score 含义相同。
+// 任意一个字符在字体中不存在时得分为 0
+func (g *GlyphOutlineMapper) GlyphSimilarity(specialUnicode, standardUnicode rune) (float64, error) {
+	if g.specialFont.Index(specialUnicode) == 0 || g.standardFont.Index(standardUnicode) == 0 {
+		return 0, nil
+	}
+	special, err := g.loadCachedGlyph(g.specialFont, "special", specialUnicode)
+	if err != nil {
+		return 0, err
+	}
+	standard, err := g.loadCachedGlyph(g.standardFont, "standard", standardUnicode)
+	if err != nil {
+		return 0, err
+	}
+	_, deviation, _ := g.matchGlyphs(special, standard)
+	return similarityScore(deviation), nil
+}
@@ -0,0 +1,142 @@
+package mapper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font/sfnt"
+)
+
+// sfnt 文件头中的版本标识
+const (
+	sfntVersionTrueType = 0x00010000
+	sfntVersionApple    = 0x74727565 // "true"
+	sfntVersionCFF      = 0x4F54544F // "OTTO"
+	sfntVersionTTC      = 0x74746366 // "ttcf"
+	sfntVersionWOFF     = 0x774F4646 // "wOFF"
+	sfntVersionWOFF2    = 0x774F4632 // "wOF2"
+)
+
+// sfntTables 读取字体的表目录，返回表名到表数据的映射。TTC 只读取其中第一个字体
+func sfntTables(data []byte) (map[string][]byte, error) {
+	if len(data) < 12 {
+		return nil, errors.New("font data is too short")
+	}
+	offset := 0
+	switch version := binary.BigEndian.Uint32(data); version {
+	case sfntVersionTrueType, sfntVersionApple, sfntVersionCFF:
+	case sfntVersionTTC:
+		if len(data) < 16 {
+			return nil, errors.New("TTC header is too short")
+		}
+		offset = int(binary.BigEndian.Uint32(data[12:]))
+		if offset <= 0 || offset+12 > len(data) {
+			return nil, errors.New("bad TTC offset")
+		}
+		if v := binary.BigEndian.Uint32(data[offset:]); v != sfntVersionTrueType && v != sfntVersionApple && v != sfntVersionCFF {
+			return nil, fmt.Errorf("%w: sfnt version %#08x", ErrUnsupportedFormat, v)
+		}
+	case sfntVersionWOFF:
+		return nil, fmt.Errorf("%w: undecoded WOFF", ErrUnsupportedFormat)
+	case sfntVersionWOFF2:
+		return nil, fmt.Errorf("%w: WOFF2", ErrUnsupportedFormat)
+	default:
+		return nil, fmt.Errorf("%w: sfnt version %#08x", ErrUnsupportedFormat, version)
+	}
+
+	n := int(binary.BigEndian.Uint16(data[offset+4:]))
+	dir := offset + 12
+	if dir+16*n > len(data) {
+		return nil, errors.New("table directory is too short")
+	}
+	tables := make(map[string][]byte, n)
+	for i := 0; i < n; i++ {
+		entry := data[dir+16*i:]
+		start := int64(binary.BigEndian.Uint32(entry[8:]))
+		end := start + int64(binary.BigEndian.Uint32(entry[12:]))
+		if end > int64(len(data)) {
+			return nil, fmt.Errorf("table %q is out of bounds", entry[:4])
+		}
+		tables[string(entry[:4])] = data[start:end]
+	}
+	return tables, nil
+}
+
+// parsedFont 是解析后的字体及其原始表数据
+type parsedFont struct {
+	source   glyphSource
+	tables   map[string][]byte
+	checksum string // 传入的原始字体数据的 SHA-256，十六进制编码
+}
+
+// parseFont 解压字体数据并检查格式，glyf 轮廓的字体交给 truetype 解析，CFF 轮廓的字体交给 sfnt 解析
+func parseFont(data []byte) (*parsedFont, error) {
+	checksum := sha256.Sum256(data)
+	data, err := decodeFontData(data)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := sfntTables(data)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := tables["glyf"]; !ok {
+		if _, ok := tables["CFF "]; ok {
+			return parseCFF(data, tables, hex.EncodeToString(checksum[:]))
+		}
+		if _, ok := tables["CFF2"]; ok {
+			return nil, fmt.Errorf("%w: CFF2", ErrUnsupportedFormat)
+		}
+		return nil, ErrNoGlyfTable
+	}
+	f, err := truetype.Parse(data)
+	if err != nil {
+		var unsupported truetype.UnsupportedError
+		if errors.As(err, &unsupported) {
+			return nil, fmt.Errorf("%w: %w", ErrUnsupportedFormat, err)
+		}
+		return nil, err
+	}
+	var numGlyphs int
+	if maxp := tables["maxp"]; len(maxp) >= 6 {
+		numGlyphs = int(binary.BigEndian.Uint16(maxp[4:]))
+	}
+	source := truetypeSource{font: f, cmap: tables["cmap"], numGlyphs: numGlyphs, faces: &facePool{font: f}, glyf: tables["glyf"], loca: tables["loca"]}
+	if head := tables["head"]; len(head) >= 52 {
+		source.longLoca = binary.BigEndian.Uint16(head[50:]) == 1
+	}
+	return &parsedFont{source: source, tables: tables, checksum: hex.EncodeToString(checksum[:])}, nil
+}
+
+func parseCFF(data []byte, tables map[string][]byte, checksum string) (*parsedFont, error) {
+	var f *sfnt.Font
+	var err error
+	if binary.BigEndian.Uint32(data) == sfntVersionTTC {
+		var c *sfnt.Collection
+		if c, err = sfnt.ParseCollection(data); err == nil {
+			f, err = c.Font(0)
+		}
+	} else {
+		f, err = sfnt.Parse(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &parsedFont{source: cffSource{font: f, cmap: tables["cmap"]}, tables: tables, checksum: checksum}, nil
+}
+
+// sameGlyphs 判断两个字体的 cmap 和轮廓表（loca、glyf 或 CFF）是否完全相同，
+// 此时每个字符都会与自身匹配，映射结果没有意义
+func sameGlyphs(a, b *parsedFont) bool {
+	for _, tag := range []string{"cmap", "loca", "glyf", "CFF "} {
+		if !bytes.Equal(a.tables[tag], b.tables[tag]) {
+			return false
+		}
+	}
+	return true
+}
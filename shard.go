@@ -0,0 +1,43 @@
+package mapper
+
+// SplitRange 把 [start, end] 切分为至多 shards 个连续的子区间，各子区间长度相差不超过 1，
+// 便于在多个 worker 上分别调用 Mapping 后再用 MergeMappings 合并。
+// shards 大于区间内的字符数时，每个子区间只包含一个字符
+func SplitRange(start, end rune, shards int) [][2]rune {
+	if end < start {
+		return nil
+	}
+	total := int64(end) - int64(start) + 1
+	if shards < 1 {
+		shards = 1
+	}
+	if int64(shards) > total {
+		shards = int(total)
+	}
+
+	size, extra := total/int64(shards), total%int64(shards)
+	ranges := make([][2]rune, 0, shards)
+	lo := int64(start)
+	for i := int64(0); i < int64(shards); i++ {
+		n := size
+		if i < extra {
+			n++
+		}
+		ranges = append(ranges, [2]rune{rune(lo), rune(lo + n - 1)})
+		lo += n
+	}
+	return ranges
+}
+
+// MergeMappings 合并多个映射结果，同一个特殊字符出现在多个结果中时以靠前的结果为准
+func MergeMappings(mappings ...map[rune]rune) map[rune]rune {
+	merged := map[rune]rune{}
+	for _, m := range mappings {
+		for special, standard := range m {
+			if _, exists := merged[special]; !exists {
+				merged[special] = standard
+			}
+		}
+	}
+	return merged
+}
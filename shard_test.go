@@ -0,0 +1,32 @@
+package mapper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitRange(t *testing.T) {
+	tests := []struct {
+		start, end rune
+		shards     int
+		want       [][2]rune
+	}{
+		{0xE000, 0xE009, 3, [][2]rune{{0xE000, 0xE003}, {0xE004, 0xE006}, {0xE007, 0xE009}}},
+		{0xE000, 0xE002, 5, [][2]rune{{0xE000, 0xE000}, {0xE001, 0xE001}, {0xE002, 0xE002}}},
+		{0xE000, 0xE00F, 0, [][2]rune{{0xE000, 0xE00F}}},
+		{0xE001, 0xE000, 2, nil},
+	}
+	for _, tt := range tests {
+		if got := SplitRange(tt.start, tt.end, tt.shards); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitRange(%U, %U, %d) = %v, want %v", tt.start, tt.end, tt.shards, got, tt.want)
+		}
+	}
+}
+
+func TestMergeMappings(t *testing.T) {
+	got := MergeMappings(map[rune]rune{0xE000: 'A'}, map[rune]rune{0xE000: 'B', 0xE001: 'C'})
+	want := map[rune]rune{0xE000: 'A', 0xE001: 'C'}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeMappings = %v, want %v", got, want)
+	}
+}
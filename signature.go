@@ -0,0 +1,129 @@
+package mapper
+
+import "math"
+
+// signatureSamples 是每个轮廓沿弧长重新采样的点数
+const signatureSamples = 64
+
+// contourSignature 是单个轮廓的形状签名
+type contourSignature struct {
+	turns  []float64 // 各采样点处的转角，单位为弧度
+	length float64   // 轮廓周长占整个字形周长的比例
+}
+
+// glyphSignature 计算字形的形状签名，步骤如下：
+//
+//  1. 按 flatness 把每个轮廓中的二次贝塞尔曲线展开为折线（两个相邻控制点之间补上隐含的曲线上的点）；
+//  2. 沿弧长把折线均匀重新采样为 signatureSamples 个点，消除编码时点数和点分布的差异；
+//  3. 计算每个采样点处前后两段的方向夹角（转角），转角序列与平移、旋转和缩放无关；
+//  4. 记录轮廓周长在整个字形周长中的占比，用于区分形状相同但大小比例不同的轮廓。
+func glyphSignature(o *outline, flatness float64) []contourSignature {
+	signatures := make([]contourSignature, 0, len(o.ends))
+	var total float64
+	start := 0
+	for _, end := range o.ends {
+		poly := flattenContour(o.points[start:end], flatness)
+		start = end
+
+		var perimeter float64
+		for i := range poly {
+			perimeter += poly[(i+1)%len(poly)].sub(poly[i]).len()
+		}
+		total += perimeter
+		signatures = append(signatures, contourSignature{
+			turns:  turningAngles(resampleContour(poly, perimeter, signatureSamples)),
+			length: perimeter,
+		})
+	}
+	if total > 0 {
+		for i := range signatures {
+			signatures[i].length /= total
+		}
+	}
+	return signatures
+}
+
+// resampleContour 沿弧长把闭合折线均匀采样为 n 个点
+func resampleContour(poly []vec, perimeter float64, n int) []vec {
+	samples := make([]vec, 0, n)
+	if len(poly) == 0 || perimeter == 0 {
+		return samples
+	}
+	step := perimeter / float64(n)
+	seg, segStart := 0, 0.0
+	for i := 0; i < n; i++ {
+		target := float64(i) * step
+		for {
+			a, b := poly[seg%len(poly)], poly[(seg+1)%len(poly)]
+			l := b.sub(a).len()
+			if target <= segStart+l || seg >= len(poly)-1 {
+				t := 0.0
+				if l > 0 {
+					t = math.Min(1, (target-segStart)/l)
+				}
+				samples = append(samples, vec{a.x + (b.x-a.x)*t, a.y + (b.y-a.y)*t})
+				break
+			}
+			segStart += l
+			seg++
+		}
+	}
+	return samples
+}
+
+// turningAngles 计算闭合折线每个顶点处的转角，结果落在 (-π, π] 区间
+func turningAngles(points []vec) []float64 {
+	n := len(points)
+	turns := make([]float64, n)
+	if n < 3 {
+		return turns
+	}
+	for i := range points {
+		in := points[i].sub(points[(i+n-1)%n])
+		out := points[(i+1)%n].sub(points[i])
+		turn := math.Atan2(out.y, out.x) - math.Atan2(in.y, in.x)
+		for turn > math.Pi {
+			turn -= 2 * math.Pi
+		}
+		for turn <= -math.Pi {
+			turn += 2 * math.Pi
+		}
+		turns[i] = turn
+	}
+	return turns
+}
+
+// signatureSimilarity 返回两个字形签名的相似度（0~1），按轮廓顺序逐个比较并取最差的一个
+func signatureSimilarity(a, b []contourSignature) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	similarity := 1.0
+	for i := range a {
+		similarity = math.Min(similarity, contourSimilarity(a[i], b[i]))
+	}
+	return similarity
+}
+
+// contourSimilarity 返回两个轮廓签名的相似度。由于轮廓的起点可能不同，
+// 会尝试所有循环位移，取转角序列平均差异最小的一种
+func contourSimilarity(a, b contourSignature) float64 {
+	n := len(a.turns)
+	if n == 0 || n != len(b.turns) {
+		return 0
+	}
+	best := math.Inf(1)
+	for shift := 0; shift < n; shift++ {
+		var diff float64
+		for i := 0; i < n; i++ {
+			diff += math.Abs(a.turns[i] - b.turns[(i+shift)%n])
+			if diff >= best {
+				break
+			}
+		}
+		best = math.Min(best, diff)
+	}
+	angle := 1 - best/float64(n)/math.Pi
+	length := 1 - math.Abs(a.length-b.length)
+	return math.Max(0, angle*length)
+}
@@ -0,0 +1,46 @@
+package mapper
+
+import "testing"
+
+func lShape() []testPoint {
+	return []testPoint{{0, 0, false}, {0, 600, false}, {200, 600, false}, {200, 200, false}, {500, 200, false}, {500, 0, false}}
+}
+
+// rotatedLShape 是 lShape 旋转 90°、放大 1.5 倍、平移并换了起点的版本，长边上还多了中点
+func rotatedLShape() []testPoint {
+	var points []testPoint
+	for _, p := range []testPoint{{200, 600, false}, {200, 200, false}, {350, 200, false}, {500, 200, false}, {500, 0, false}, {0, 0, false}, {0, 300, false}, {0, 600, false}} {
+		points = append(points, testPoint{x: 1000 - p.y*3/2, y: 100 + p.x*3/2})
+	}
+	return points
+}
+
+func TestGlyphOutlineMapper_ShapeSignatureMatching(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{rotatedLShape()}, advance: 1000},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(0, 0, 600)}, advance: 1000},
+		{contours: [][]testPoint{triangle(0, 0, 600)}, advance: 1000},
+		{contours: [][]testPoint{lShape()}, advance: 1000},
+	}, map[rune]rune{'S': 1, 'T': 2, 'L': 3})
+
+	plain, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := plain.MappingRune(0xE000); ok {
+		t.Fatal("coordinate comparison should not match a rotated and rescaled glyph")
+	}
+
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithShapeSignatureMatching(0.95))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, standardRune, ok := mapper.MappingRune(0xE000); !ok || standardRune != 'L' {
+		t.Fatalf("got %q (ok=%v), want 'L'", standardRune, ok)
+	}
+	if mapper.GlyphOutlineEqual(0xE000, 'S') || mapper.GlyphOutlineEqual(0xE000, 'T') {
+		t.Error("signature matching accepted a different shape")
+	}
+}
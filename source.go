@@ -0,0 +1,279 @@
+package mapper
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// outline 是按某个尺寸加载后的字形轮廓，坐标的 y 轴向上
+type outline struct {
+	points []truetype.Point // Flags 最低位为 1 表示点在曲线上，否则是二次贝塞尔曲线的控制点
+	ends   []int            // 每个轮廓最后一个点之后的下标
+}
+
+// glyphSource 是比较逻辑所需的字体能力，TrueType 和 CFF 轮廓的字体分别有各自的实现
+type glyphSource interface {
+	// Index 返回字符对应的字形索引，字符不存在时返回 0
+	Index(r rune) int
+	// Has 判断字符是否有可见的字形，字形数据损坏时返回错误
+	Has(r rune) (bool, error)
+	// Load 以 ppem 的尺寸加载字形轮廓
+	Load(index int, ppem fixed.Int26_6, hinting font.Hinting) (*outline, error)
+	// UnitsPerEm 返回字体的 unitsPerEm
+	UnitsPerEm() int
+	// Runes 返回 cmap 中编码的全部字符，按码位升序排列
+	Runes() []rune
+	// NumGlyphs 返回字体中的字形数量，字形索引的范围是 [0, NumGlyphs)
+	NumGlyphs() int
+}
+
+// encodedRunes 返回 cmap 中编码、并且在 s 中确实对应到某个字形的字符
+func encodedRunes(s glyphSource, cmap []byte) []rune {
+	return slices.DeleteFunc(cmapRunes(cmap), func(r rune) bool { return s.Index(r) == 0 })
+}
+
+// truetypeSource 用 truetype 读取 glyf 表中的轮廓
+type truetypeSource struct {
+	font      *truetype.Font
+	cmap      []byte
+	numGlyphs int // truetype 没有导出字形数量，从 maxp 表读取
+	faces     *facePool
+	glyf      []byte // 原始的 glyf 和 loca 表，见 RawGlyph
+	loca      []byte
+	longLoca  bool // head 表的 indexToLocFormat 为 1
+}
+
+// facePool 复用 Has 使用的 font.Face。truetype 的 Face 不能并发使用，每次取出一个独占的
+type facePool struct {
+	font *truetype.Font
+	mu   sync.Mutex
+	free []font.Face
+}
+
+func (p *facePool) get() font.Face {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if n := len(p.free); n > 0 {
+		face := p.free[n-1]
+		p.free = p.free[:n-1]
+		return face
+	}
+	return truetype.NewFace(p.font, &truetype.Options{Size: 12})
+}
+
+func (p *facePool) put(face font.Face) {
+	p.mu.Lock()
+	p.free = append(p.free, face)
+	p.mu.Unlock()
+}
+
+// Close 关闭所有空闲的 Face，之后 get 会重新创建
+func (p *facePool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, face := range p.free {
+		face.Close()
+	}
+	p.free = nil
+	return nil
+}
+
+func (s truetypeSource) Close() error {
+	return s.faces.Close()
+}
+
+func (s truetypeSource) Index(r rune) int {
+	return int(s.font.Index(r))
+}
+
+func (s truetypeSource) UnitsPerEm() int {
+	return int(s.font.FUnitsPerEm())
+}
+
+func (s truetypeSource) Runes() []rune {
+	return encodedRunes(s, s.cmap)
+}
+
+func (s truetypeSource) NumGlyphs() int {
+	return s.numGlyphs
+}
+
+// Load 损坏的字形数据会让 truetype 直接 panic，这里将其转换为错误
+func (s truetypeSource) Load(index int, ppem fixed.Int26_6, hinting font.Hinting) (o *outline, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			o, err = nil, fmt.Errorf("malformed glyph data: %v", r)
+		}
+	}()
+	var buf truetype.GlyphBuf
+	if err := buf.Load(s.font, ppem, truetype.Index(index), hinting); err != nil {
+		return nil, err
+	}
+	return &outline{points: buf.Points, ends: buf.Ends}, nil
+}
+
+func (s truetypeSource) Has(char rune) (has bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			has, err = false, fmt.Errorf("malformed glyph data: %v", r)
+		}
+	}()
+
+	// 方法1：检查字体索引
+	index := s.font.Index(char)
+	if index == 0 && char != 0 {
+		return false, nil
+	}
+
+	// 方法2：检查字形边界和advance
+	face := s.faces.get()
+	bounds, advance, ok := face.GlyphBounds(char)
+	// GlyphBounds panic 时不会执行到这里，状态不确定的 face 直接被丢弃
+	s.faces.put(face)
+	if !ok {
+		return false, nil
+	}
+
+	// 方法3：检查是否有实际的可视字形
+	if bounds.Empty() && advance == 0 {
+		return false, nil
+	}
+
+	// 方法4：对于私有使用区域（包括补充平面的私有使用区）的特殊检查
+	if isPrivateUse(char) {
+		// 私有使用区域，即使bounds为空也可能有字形
+		if advance > 0 {
+			return true, nil
+		}
+		if !bounds.Empty() {
+			return true, nil
+		}
+		if index > 0 {
+			return true, nil
+		}
+		return false, nil
+	}
+
+	// 一般情况下，有索引就认为存在
+	if index > 0 {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// cffSource 用 x/image/font/sfnt 读取 CFF 表中的轮廓。每次调用都使用独立的 sfnt.Buffer，可以并发使用
+type cffSource struct {
+	font *sfnt.Font
+	cmap []byte
+}
+
+func (s cffSource) Index(r rune) int {
+	index, err := s.font.GlyphIndex(nil, r)
+	if err != nil {
+		return 0
+	}
+	return int(index)
+}
+
+func (s cffSource) UnitsPerEm() int {
+	return int(s.font.UnitsPerEm())
+}
+
+func (s cffSource) Runes() []rune {
+	return encodedRunes(s, s.cmap)
+}
+
+func (s cffSource) NumGlyphs() int {
+	return s.font.NumGlyphs()
+}
+
+// Load CFF 没有 hinting 指令，hinting 参数被忽略
+func (s cffSource) Load(index int, ppem fixed.Int26_6, _ font.Hinting) (*outline, error) {
+	segments, err := s.font.LoadGlyph(nil, sfnt.GlyphIndex(index), ppem, nil)
+	if err != nil {
+		return nil, err
+	}
+	return segmentsOutline(segments), nil
+}
+
+func (s cffSource) Has(r rune) (bool, error) {
+	index := s.Index(r)
+	if index == 0 {
+		return false, nil
+	}
+	segments, err := s.font.LoadGlyph(nil, sfnt.GlyphIndex(index), fixed.I(12), nil)
+	if err != nil {
+		return false, err
+	}
+	advance, err := s.font.GlyphAdvance(nil, sfnt.GlyphIndex(index), fixed.I(12), font.HintingNone)
+	if err != nil {
+		return false, err
+	}
+	return len(segments) > 0 || advance > 0, nil
+}
+
+// segmentsOutline 把 sfnt 的路径转换为与 glyf 相同的二次曲线轮廓：y 轴翻转为向上，
+// 三次曲线在中点处拆成两段二次曲线近似，与起点重合的闭合点被去掉
+func segmentsOutline(segments sfnt.Segments) *outline {
+	o := &outline{}
+	point := func(p fixed.Point26_6, on bool) truetype.Point {
+		tp := truetype.Point{X: p.X, Y: -p.Y}
+		if on {
+			tp.Flags = 1
+		}
+		return tp
+	}
+	closeContour := func() {
+		start := 0
+		if len(o.ends) > 0 {
+			start = o.ends[len(o.ends)-1]
+		}
+		if len(o.points)-start > 1 {
+			first, last := o.points[start], o.points[len(o.points)-1]
+			if first == last {
+				o.points = o.points[:len(o.points)-1]
+			}
+		}
+		if len(o.points) > start {
+			o.ends = append(o.ends, len(o.points))
+		}
+	}
+	var current fixed.Point26_6
+	for _, seg := range segments {
+		switch seg.Op {
+		case sfnt.SegmentOpMoveTo:
+			closeContour()
+			o.points = append(o.points, point(seg.Args[0], true))
+			current = seg.Args[0]
+		case sfnt.SegmentOpLineTo:
+			o.points = append(o.points, point(seg.Args[0], true))
+			current = seg.Args[0]
+		case sfnt.SegmentOpQuadTo:
+			o.points = append(o.points, point(seg.Args[0], false), point(seg.Args[1], true))
+			current = seg.Args[1]
+		case sfnt.SegmentOpCubeTo:
+			p0, c1, c2, p3 := current, seg.Args[0], seg.Args[1], seg.Args[2]
+			// 在 t=0.5 处拆分，每一半用一段二次曲线近似
+			m01, m12, m23 := midpoint(p0, c1), midpoint(c1, c2), midpoint(c2, p3)
+			a, b := midpoint(m01, m12), midpoint(m12, m23)
+			mid := midpoint(a, b)
+			q1 := fixed.Point26_6{X: (3*(m01.X+a.X) - p0.X - mid.X) / 4, Y: (3*(m01.Y+a.Y) - p0.Y - mid.Y) / 4}
+			q2 := fixed.Point26_6{X: (3*(b.X+m23.X) - mid.X - p3.X) / 4, Y: (3*(b.Y+m23.Y) - mid.Y - p3.Y) / 4}
+			o.points = append(o.points, point(q1, false), point(mid, true), point(q2, false), point(p3, true))
+			current = p3
+		}
+	}
+	closeContour()
+	return o
+}
+
+func midpoint(a, b fixed.Point26_6) fixed.Point26_6 {
+	return fixed.Point26_6{X: (a.X + b.X) / 2, Y: (a.Y + b.Y) / 2}
+}
@@ -0,0 +1,147 @@
+package mapper
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// fakeSource 是测试用的 glyphSource，轮廓直接以字体单位给出且不随 ppem 缩放
+type fakeSource struct {
+	cmap     map[rune]int
+	outlines map[int][][]testPoint
+	errs     map[int]error
+}
+
+func (s fakeSource) Index(r rune) int { return s.cmap[r] }
+
+func (s fakeSource) Has(r rune) (bool, error) { return s.cmap[r] != 0, nil }
+
+func (s fakeSource) UnitsPerEm() int { return 1000 }
+
+func (s fakeSource) Runes() []rune {
+	runes := slices.Collect(maps.Keys(s.cmap))
+	slices.Sort(runes)
+	return runes
+}
+
+func (s fakeSource) NumGlyphs() int {
+	return slices.Max(append(slices.Collect(maps.Keys(s.outlines)), 0)) + 1
+}
+
+func (s fakeSource) Load(index int, _ fixed.Int26_6, _ font.Hinting) (*outline, error) {
+	if err := s.errs[index]; err != nil {
+		return nil, err
+	}
+	o := &outline{}
+	for _, c := range s.outlines[index] {
+		o.points = append(o.points, toTruetypePoints(c)...)
+		o.ends = append(o.ends, len(o.points))
+	}
+	return o, nil
+}
+
+func TestGlyphOutlineMapper_FakeSource(t *testing.T) {
+	broken := errors.New("broken glyph")
+	special := fakeSource{
+		cmap:     map[rune]int{0xE000: 1, 0xE001: 2},
+		outlines: map[int][][]testPoint{1: {square(0, 0, 500)}, 2: {triangle(0, 0, 600)}},
+	}
+	standard := fakeSource{
+		cmap:     map[rune]int{'A': 1, 'B': 2, 'C': 3},
+		outlines: map[int][][]testPoint{1: {triangle(0, 0, 500)}, 2: {square(0, 0, 500)}},
+		errs:     map[int]error{3: broken},
+	}
+	mapper := newGlyphOutlineMapper(special, standard)
+
+	got, errs := mapper.MappingWithErrors(0xE000, 0xE001)
+	if want := map[rune]rune{0xE000: 'B'}; len(got) != len(want) || got[0xE000] != 'B' {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if len(errs) != 1 || errs[0].Font != "standard" || errs[0].Rune != 'C' || !errors.Is(errs[0], broken) {
+		t.Errorf("got errors %v, want one for standard 'C'", errs)
+	}
+
+	mapper.Mapping(0xE000, 0xE001)
+	if errs := mapper.Errors(); len(errs) != 1 || !errors.Is(errs[0], broken) {
+		t.Errorf("Errors() = %v, want one for standard 'C'", errs)
+	}
+	if _, err := mapper.GlyphOutlineEqualE(0xE000, 'C'); !errors.Is(err, broken) {
+		t.Errorf("GlyphOutlineEqualE error = %v, want %v", err, broken)
+	}
+	if equal, err := mapper.GlyphOutlineEqualE(0xE000, 'B'); err != nil || !equal {
+		t.Errorf("GlyphOutlineEqualE = %v, %v, want true, nil", equal, err)
+	}
+}
+
+func TestSegmentsOutline(t *testing.T) {
+	pt := func(x, y int) fixed.Point26_6 { return fixed.Point26_6{X: fixed.Int26_6(x), Y: fixed.Int26_6(y)} }
+	// sfnt 的 y 轴向下
+	segments := sfnt.Segments{
+		{Op: sfnt.SegmentOpMoveTo, Args: [3]fixed.Point26_6{pt(0, 0)}},
+		{Op: sfnt.SegmentOpLineTo, Args: [3]fixed.Point26_6{pt(0, -800)}},
+		{Op: sfnt.SegmentOpCubeTo, Args: [3]fixed.Point26_6{pt(800, -800), pt(800, 0), pt(0, 0)}},
+		{Op: sfnt.SegmentOpMoveTo, Args: [3]fixed.Point26_6{pt(100, -100)}},
+		{Op: sfnt.SegmentOpQuadTo, Args: [3]fixed.Point26_6{pt(200, -200), pt(300, -100)}},
+	}
+	o := segmentsOutline(segments)
+
+	if want := []int{5, 8}; !slices.Equal(o.ends, want) {
+		t.Fatalf("got ends %v, want %v", o.ends, want)
+	}
+	want := []truetype.Point{
+		{X: 0, Y: 0, Flags: 1},
+		{X: 0, Y: 800, Flags: 1},
+		{X: 600, Y: 750},
+		{X: 600, Y: 400, Flags: 1}, // 三次曲线在 t=0.5 处的点
+		{X: 600, Y: 50},
+		{X: 100, Y: 100, Flags: 1},
+		{X: 200, Y: 200},
+		{X: 300, Y: 100, Flags: 1},
+	}
+	if !slices.Equal(o.points, want) {
+		t.Errorf("got points %v, want %v", o.points, want)
+	}
+}
+
+func TestGlyphOutlineMapper_MappingRuneE(t *testing.T) {
+	broken := errors.New("broken glyph")
+	special := fakeSource{
+		cmap:     map[rune]int{0xE000: 1, 0xE001: 2, 0xE002: 3},
+		outlines: map[int][][]testPoint{1: {square(0, 0, 500)}, 2: {triangle(0, 0, 600)}},
+		errs:     map[int]error{3: broken},
+	}
+	standard := fakeSource{
+		cmap:     map[rune]int{'A': 1},
+		outlines: map[int][][]testPoint{1: {square(0, 0, 500)}},
+	}
+	mapper := newGlyphOutlineMapper(special, standard)
+
+	if got, err := mapper.MappingRuneE(0xE000); err != nil || got != 'A' {
+		t.Errorf("U+E000: got %q, %v, want 'A'", got, err)
+	}
+	tests := []struct {
+		r    rune
+		want error
+	}{
+		{0xE001, ErrNoMatch},
+		{0xE002, ErrGlyphLoad},
+		{0xE003, ErrNoGlyph},
+	}
+	for _, tt := range tests {
+		_, err := mapper.MappingRuneE(tt.r)
+		var runeErr *RuneError
+		if !errors.Is(err, tt.want) || !errors.As(err, &runeErr) || runeErr.Rune != tt.r {
+			t.Errorf("%U: got %v, want a RuneError wrapping %v", tt.r, err, tt.want)
+		}
+	}
+	if _, err := mapper.MappingRuneE(0xE002); !errors.Is(err, broken) {
+		t.Errorf("U+E002: got %v, want it to wrap %v", err, broken)
+	}
+}
@@ -0,0 +1,26 @@
+package mapper
+
+// NewSpecialFontMapper 创建在两个混淆字体之间映射的 mapper：把字体 a 中的字符映射到字体 b 中轮廓相同的字符。
+// 网站更换了混淆字体、而旧字体 b 已经有映射结果时，可以用 Mapping.Chain 把 a => b 与 b => 标准字符连接起来，
+// 不需要重新与完整的标准字体比较。候选字符只限于 b 的 cmap 中编码的字符
+func NewSpecialFontMapper(a, b []byte, opts ...Option) (*GlyphOutlineMapper, error) {
+	g, err := NewGlyphOutlineMapper(a, b, opts...)
+	if err != nil {
+		return nil, err
+	}
+	g.SetCandidateRanges(runeRanges(g.standardFont.Runes()))
+	return g, nil
+}
+
+// runeRanges 把升序排列的字符合并为尽量少的连续范围
+func runeRanges(runes []rune) []RuneRange {
+	var ranges []RuneRange
+	for _, r := range runes {
+		if n := len(ranges); n > 0 && ranges[n-1].End+1 == r {
+			ranges[n-1].End = r
+			continue
+		}
+		ranges = append(ranges, RuneRange{Start: r, End: r})
+	}
+	return ranges
+}
@@ -0,0 +1,42 @@
+package mapper
+
+import "testing"
+
+func TestNewSpecialFontMapper(t *testing.T) {
+	// 旧字体与新字体使用不同的码位编码同一组字形
+	oldFont := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+	newFont := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE100: 1, 0xE101: 2})
+	mapper, err := NewSpecialFontMapper(newFont, oldFont)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newToOld := mapper.MappingAll()
+	if len(newToOld) != 2 || newToOld[0xE100] != 0xE001 || newToOld[0xE101] != 0xE000 {
+		t.Fatalf("got %v, want U+E100 => U+E001, U+E101 => U+E000", newToOld)
+	}
+
+	oldToStandard := Mapping{0xE000: '口', 0xE001: '△'}
+	got := newToOld.Chain(oldToStandard)
+	if len(got) != 2 || got[0xE100] != '△' || got[0xE101] != '口' {
+		t.Errorf("Chain = %v, want U+E100 => '△', U+E101 => '口'", got)
+	}
+}
+
+func TestRuneRanges(t *testing.T) {
+	got := runeRanges([]rune{1, 2, 3, 5, 7, 8})
+	want := []RuneRange{{1, 3}, {5, 5}, {7, 8}}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("range %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
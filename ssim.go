@@ -0,0 +1,64 @@
+package mapper
+
+import (
+	"image"
+	"math"
+)
+
+// ssimWindow 是计算 SSIM 时局部窗口的边长，窗口每次移动半个边长
+const ssimWindow = 8
+
+// SSIMMatcher 返回按结构相似性（SSIM）比较的 GlyphMatcher：两个字形以与 RasterMatcher 相同的方式渲染为灰度位图，
+// 在 8×8 的局部窗口上比较亮度、对比度和结构，取全部窗口的平均值。SSIM 不低于 threshold（例如 0.9）时认为一致。
+// 它能识别经过 hinting 或者轻微重绘的字形，但每次比较都要渲染并扫描两张位图，代价最高，
+// 适合放在 AnyMatcher 的最后，只在前面便宜的比较都失败时才使用
+func SSIMMatcher(size int, threshold float64) GlyphMatcher {
+	size = max(size, ssimWindow)
+	return GlyphMatcherFunc(func(special, standard GlyphData) (bool, float64) {
+		if len(special.Points) == 0 || len(standard.Points) == 0 {
+			if len(special.Points) != len(standard.Points) {
+				return false, math.Inf(1)
+			}
+			return true, 0
+		}
+		similarity := ssim(rasterizePair(special, standard, size))
+		if similarity < threshold {
+			return false, math.Inf(1)
+		}
+		return true, relativeDeviation(1-similarity, 1, 1-threshold)
+	})
+}
+
+// ssim 计算两张大小相同的位图的平均 SSIM
+func ssim(a, b *image.Alpha) float64 {
+	const (
+		c1 = (0.01 * 255) * (0.01 * 255)
+		c2 = (0.03 * 255) * (0.03 * 255)
+	)
+	size := a.Bounds().Dx()
+	var total float64
+	windows := 0
+	for y := 0; y+ssimWindow <= size; y += ssimWindow / 2 {
+		for x := 0; x+ssimWindow <= size; x += ssimWindow / 2 {
+			var sumA, sumB, sumAA, sumBB, sumAB float64
+			for dy := range ssimWindow {
+				for dx := range ssimWindow {
+					pa := float64(a.Pix[(y+dy)*a.Stride+x+dx])
+					pb := float64(b.Pix[(y+dy)*b.Stride+x+dx])
+					sumA += pa
+					sumB += pb
+					sumAA += pa * pa
+					sumBB += pb * pb
+					sumAB += pa * pb
+				}
+			}
+			n := float64(ssimWindow * ssimWindow)
+			meanA, meanB := sumA/n, sumB/n
+			varA, varB := sumAA/n-meanA*meanA, sumBB/n-meanB*meanB
+			cov := sumAB/n - meanA*meanB
+			total += (2*meanA*meanB + c1) * (2*cov + c2) / ((meanA*meanA + meanB*meanB + c1) * (varA + varB + c2))
+			windows++
+		}
+	}
+	return total / float64(windows)
+}
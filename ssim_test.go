@@ -0,0 +1,44 @@
+package mapper
+
+import "testing"
+
+func TestSSIMMatcher(t *testing.T) {
+	// 特殊字形是轻微重绘的正方形：两个角向内收了几个单位，点数也不同
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{{{100, 104, false}, {100, 600, false}, {596, 600, false}, {600, 596, false}, {600, 100, false}, {104, 100, false}}}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+
+	plain, err := NewGlyphOutlineMapper(special, standard, WithMatchers(OutlineMatcher(10)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, standardRune, ok := plain.MappingRune(0xE000); ok {
+		t.Errorf("redrawn glyph matched %q with point comparison", standardRune)
+	}
+
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithMatchers(AnyMatcher(OutlineMatcher(10), SSIMMatcher(64, 0.9))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, standardRune, ok := mapper.MappingRune(0xE000); !ok || standardRune != 'B' {
+		t.Errorf("got %q (ok=%v), want 'B'", standardRune, ok)
+	}
+}
+
+func TestAnyMatcher(t *testing.T) {
+	never := GlyphMatcherFunc(func(_, _ GlyphData) (bool, float64) { return false, 0 })
+	always := GlyphMatcherFunc(func(_, _ GlyphData) (bool, float64) { return true, 0.5 })
+	if ok, deviation := AnyMatcher(never, always).Match(GlyphData{}, GlyphData{}); !ok || deviation != 0.5 {
+		t.Errorf("got %v, %v, want true, 0.5", ok, deviation)
+	}
+	if ok, _ := AnyMatcher(never).Match(GlyphData{}, GlyphData{}); ok {
+		t.Error("AnyMatcher(never) matched")
+	}
+	if ok, _ := AnyMatcher().Match(GlyphData{}, GlyphData{}); ok {
+		t.Error("empty AnyMatcher matched")
+	}
+}
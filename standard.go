@@ -0,0 +1,44 @@
+package mapper
+
+import (
+	"fmt"
+	"slices"
+)
+
+// namedFont 是一个带名字的标准字体，名字会出现在 MappingResult.StandardFont 和 GlyphLoadError.Font 中
+type namedFont struct {
+	name     string
+	source   glyphSource
+	checksum string // 字体原始数据的 SHA-256，未知时为空
+}
+
+// AddStandardFont 追加一个标准字体。查找候选字符时按优先级依次搜索：先是创建 mapper 时传入的标准字体
+// （名字为 "standard"），再按添加的顺序搜索追加的字体。name 不能为空，也不能与已有的字体重名。
+//
+// 候选范围仍是默认的 [0, 最后一个字符] 时会扩展到覆盖新字体的全部字符。
+// 不能与映射并发调用
+func (g *GlyphOutlineMapper) AddStandardFont(data []byte, name string) error {
+	if name == "" || name == "special" || slices.ContainsFunc(g.standardFonts(), func(f namedFont) bool { return f.name == name }) {
+		return fmt.Errorf("invalid standard font name %q", name)
+	}
+	parsed, err := parseFont(data)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrStandardFontParse, name, err)
+	}
+	g.extraStandardFonts = append(g.extraStandardFonts, namedFont{name: name, source: parsed.source, checksum: parsed.checksum})
+
+	lastRune := findLastRune(parsed.source)
+	if lastRune > g.standardFontLastRune {
+		if len(g.candidateRanges) == 1 && g.candidateRanges[0] == (RuneRange{Start: 0, End: g.standardFontLastRune}) {
+			g.candidateRanges[0].End = lastRune
+		}
+		g.standardFontLastRune = lastRune
+	}
+	g.resetCache()
+	return nil
+}
+
+// standardFonts 按优先级返回全部标准字体
+func (g *GlyphOutlineMapper) standardFonts() []namedFont {
+	return append([]namedFont{{name: "standard", source: g.standardFont, checksum: g.standardChecksum}}, g.extraStandardFonts...)
+}
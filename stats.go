@@ -0,0 +1,52 @@
+package mapper
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// MappingStats 是一次批量映射的统计，用于判断是否需要补充标准字体或放宽容差
+type MappingStats struct {
+	Examined  int // 特殊字体中存在、实际参与比较的字符数
+	Matched   int // 找到匹配的字符数
+	Unmatched int // 参与比较但没有找到匹配的字符数
+	Ambiguous int // 有多个候选在容差之内的字符数
+	Errors    int // 加载失败的字形数
+
+	Elapsed time.Duration // 整次映射的耗时
+	// 单个字符比较耗时的分布，第一个字符通常包含建立标准字体缓存的时间
+	MinTime, MedianTime, P90Time, MaxTime time.Duration
+}
+
+// MappingWithStats 与 MappingDetailed 相同，同时返回这一次映射的统计，并发的多次调用各自统计。
+// ctx 被取消时返回已经完成的部分结果以及 ctx.Err()，取消时还没有比较完的字符不计入统计；
+// 从 WithCheckpoint 的进度中沿用的结果也不计入统计
+func (g *GlyphOutlineMapper) MappingWithStats(ctx context.Context, start, end rune) ([]MappingResult, MappingStats, error) {
+	results, _, stats, err := g.mappingDetailed(ctx, start, end, true, nil)
+	return results, stats, err
+}
+
+// newMappingStats 用比较完的字符中找到的匹配 results 和每个字符的比较耗时 durations 计算统计
+func newMappingStats(results []MappingResult, errs []*GlyphLoadError, durations []time.Duration, elapsed time.Duration) MappingStats {
+	stats := MappingStats{
+		Examined: len(durations),
+		Matched:  len(results),
+		Errors:   len(errs),
+		Elapsed:  elapsed,
+	}
+	stats.Unmatched = max(stats.Examined-stats.Matched, 0)
+	for _, result := range results {
+		if result.Ambiguous {
+			stats.Ambiguous++
+		}
+	}
+	if len(durations) > 0 {
+		slices.Sort(durations)
+		stats.MinTime = durations[0]
+		stats.MedianTime = durations[len(durations)/2]
+		stats.P90Time = durations[len(durations)*9/10]
+		stats.MaxTime = durations[len(durations)-1]
+	}
+	return stats
+}
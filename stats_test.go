@@ -0,0 +1,62 @@
+package mapper
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGlyphOutlineMapper_Stats(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, stats, err := mapper.MappingWithStats(context.Background(), 0xE000, 0xE00F)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("got %v, %v, want one result", results, err)
+	}
+	if stats.Examined != 2 || stats.Matched != 1 || stats.Unmatched != 1 || stats.Ambiguous != 1 || stats.Errors != 0 {
+		t.Errorf("got %+v, want 2 examined, 1 matched, 1 unmatched, 1 ambiguous", stats)
+	}
+	if got := mapper.AmbiguousRunes(); len(got) != 1 || got[0] != 0xE000 {
+		t.Errorf("AmbiguousRunes() = %U, want [U+E000]", got)
+	}
+	if stats.MinTime > stats.MedianTime || stats.MedianTime > stats.P90Time || stats.P90Time > stats.MaxTime || stats.MaxTime > stats.Elapsed {
+		t.Errorf("timing distribution out of order: %+v", stats)
+	}
+}
+
+func TestGlyphOutlineMapper_MappingWithStatsCancelled(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, stats, err := mapper.MappingWithStats(ctx, 0xE000, 0xE00F)
+	if err != context.Canceled {
+		t.Fatalf("error = %v, want context.Canceled", err)
+	}
+	if stats.Examined != 0 || stats.Matched != 0 || stats.Unmatched != 0 {
+		t.Errorf("cancelled runes counted in stats: %+v", stats)
+	}
+	// 之后的调用只统计自己的字符
+	if _, stats, _ := mapper.MappingWithStats(context.Background(), 0xE000, 0xE000); stats.Examined != 1 || stats.Matched != 1 {
+		t.Errorf("got %+v, want 1 examined, 1 matched", stats)
+	}
+}
@@ -0,0 +1,25 @@
+package mapper
+
+// MatchStrategy 决定在标准字体中找到多个匹配的候选字符时选择哪一个
+type MatchStrategy int
+
+const (
+	// FirstMatch 返回按候选顺序第一个匹配的字符，速度最快
+	FirstMatch MatchStrategy = iota
+	// BestMatch 扫描全部候选字符，返回偏差最小的一个，偏差相同时取候选顺序中靠前的一个
+	BestMatch
+)
+
+// negligibleDeviation 是可以视为完全一致的偏差上限，偏差以容差为单位，
+// 即平均每个点的误差不超过容差的 1%。BestMatch 遇到这样的候选会立即停止扫描
+const negligibleDeviation = 0.01
+
+func (s MatchStrategy) String() string {
+	switch s {
+	case FirstMatch:
+		return "first-match"
+	case BestMatch:
+		return "best-match"
+	}
+	return "unknown"
+}
@@ -0,0 +1,153 @@
+package mapper
+
+import "testing"
+
+func TestGlyphOutlineMapper_BestMatch(t *testing.T) {
+	// 16384 unitsPerEm 下 1~2 个单位的差异都在默认容差之内
+	special := buildTestFont(16384, []testGlyph{
+		{contours: [][]testPoint{square(1000, 1000, 8000)}, advance: 10000},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(16384, []testGlyph{
+		{contours: [][]testPoint{square(1000, 1000, 8002)}, advance: 10000},
+		{contours: [][]testPoint{square(1000, 1000, 8001)}, advance: 10000},
+		{contours: [][]testPoint{square(1000, 1000, 8000)}, advance: 10000},
+	}, map[rune]rune{'A': 1, 'B': 2, 'C': 3})
+
+	tests := []struct {
+		strategy MatchStrategy
+		want     rune
+	}{
+		{FirstMatch, 'A'},
+		{BestMatch, 'C'},
+	}
+	for _, tt := range tests {
+		mapper, err := NewGlyphOutlineMapper(special, standard, WithMatchStrategy(tt.strategy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, standardRune, ok := mapper.MappingRune(0xE000); !ok || standardRune != tt.want {
+			t.Errorf("%v: got %q (ok=%v), want %q", tt.strategy, standardRune, ok, tt.want)
+		}
+		result, ok := mapper.MappingRuneResult(0xE000)
+		if !ok || result.Standard != tt.want || !result.Ambiguous {
+			t.Errorf("%v: MappingRuneResult = %+v (ok=%v), want ambiguous %q", tt.strategy, result, ok, tt.want)
+		}
+		if tt.strategy == BestMatch && result.Score != 1 {
+			t.Errorf("%v: score = %v, want 1 for the exact match", tt.strategy, result.Score)
+		}
+	}
+}
+
+func TestGlyphOutlineMapper_MappingRuneAmong(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2, 'C': 3})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r, ok, err := mapper.MappingRuneAmong(0xE000, []rune{'Z', 'B', 'C'}); err != nil || !ok || r != 'C' {
+		t.Errorf("got %q, %v, %v; want 'C'", r, ok, err)
+	}
+	if _, ok, err := mapper.MappingRuneAmong(0xE000, []rune{'B'}); err != nil || ok {
+		t.Errorf("unexpected match among non-matching candidates (err=%v)", err)
+	}
+	if mapper.cache != nil {
+		t.Error("MappingRuneAmong should not build the full standard cache")
+	}
+}
+
+func TestGlyphOutlineMapper_AmbiguousRunes(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2, 'C': 3})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := mapper.MappingDetailed(0xE000, 0xE001)
+	if len(results) != 2 || results[0].Standard != 'A' || !results[0].Ambiguous || results[1].Ambiguous {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if got := mapper.AmbiguousRunes(); len(got) != 1 || got[0] != 0xE000 {
+		t.Errorf("AmbiguousRunes = %U, want [U+E000]", got)
+	}
+}
+
+func TestWithNearestFallback(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 200)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 480)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2, 'C': 3})
+
+	plain, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := plain.MappingRune(0xE000); ok {
+		t.Fatal("matched without fallback")
+	}
+
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithNearestFallback())
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := mapper.MappingDetailed(0xE000, 0xE000)
+	if len(results) != 1 || results[0].Standard != 'B' || !results[0].LowConfidence || results[0].Score >= 1 {
+		t.Errorf("got %+v, want a low-confidence match to 'B'", results)
+	}
+}
+
+func TestGlyphOutlineMapper_SetMatchThreshold(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	// 一个点偏离 2 个单位，超出了默认的逐点容差，平均偏差是容差的 3.2 倍，得分约为 0.24
+	moved := square(100, 100, 500)
+	moved[2].x += 2
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{moved}, advance: 800},
+	}, map[rune]rune{'A': 1})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		threshold float64
+		want      bool
+	}{
+		{0, false},
+		{0.2, true},
+		{0.5, false},
+	}
+	for _, tt := range tests {
+		mapper.SetMatchThreshold(tt.threshold)
+		if got := mapper.GlyphOutlineEqual(0xE000, 'A'); got != tt.want {
+			t.Errorf("threshold %v: GlyphOutlineEqual = %v, want %v", tt.threshold, got, tt.want)
+		}
+		if _, _, ok := mapper.MappingRune(0xE000); ok != tt.want {
+			t.Errorf("threshold %v: MappingRune ok = %v, want %v", tt.threshold, ok, tt.want)
+		}
+	}
+	if got := mapper.Config().MatchThreshold; got != 0.5 {
+		t.Errorf("Config().MatchThreshold = %v, want 0.5", got)
+	}
+}
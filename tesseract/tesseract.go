@@ -0,0 +1,112 @@
+// Package tesseract 用 Tesseract 的命令行程序实现 mapper.GlyphRecognizer，作为 mapper.WithOCRFallback 的识别器。
+// 每次识别启动一次 tesseract 进程，图像以 PNG 格式从标准输入传入，不需要 cgo，也不依赖 gosseract，例如
+//
+//	g, err := mapper.NewGlyphOutlineMapper(special, standard, mapper.WithOCRFallback(tesseract.New("chi_sim"), 64))
+package tesseract
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os/exec"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	mapper "github.com/bestnite/font-mapper"
+)
+
+// Recognizer 调用 tesseract 识别单个字形，可以并发调用
+type Recognizer struct {
+	// Path 是 tesseract 可执行文件的路径，为空时在 PATH 中查找 "tesseract"
+	Path string
+	// Languages 是 -l 参数，例如 "chi_sim" 或 "chi_sim+eng"，为空时使用 tesseract 的默认语言
+	Languages string
+	// Args 是额外的命令行参数，例如 "--tessdata-dir", "/usr/share/tessdata"，放在输出格式之前
+	Args []string
+}
+
+var _ mapper.GlyphRecognizer = (*Recognizer)(nil)
+
+// New 返回使用 languages 模型、在 PATH 中查找 tesseract 的 Recognizer
+func New(languages string) *Recognizer {
+	return &Recognizer{Languages: languages}
+}
+
+// Recognize 以单个字符的页面分割模式（--psm 10）识别 img，返回置信度最高的字符，置信度换算到 0~1。
+// 没有识别出字符时返回 0 和 nil 错误
+func (r *Recognizer) Recognize(img image.Image) (rune, float64, error) {
+	var input bytes.Buffer
+	if err := png.Encode(&input, img); err != nil {
+		return 0, 0, fmt.Errorf("encode glyph image: %w", err)
+	}
+	path := r.Path
+	if path == "" {
+		path = "tesseract"
+	}
+	args := []string{"stdin", "stdout", "--psm", "10"}
+	if r.Languages != "" {
+		args = append(args, "-l", r.Languages)
+	}
+	args = append(append(args, r.Args...), "tsv")
+
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = &input
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return 0, 0, fmt.Errorf("run tesseract: %w: %s", err, msg)
+		}
+		return 0, 0, fmt.Errorf("run tesseract: %w", err)
+	}
+	return parseTSV(output)
+}
+
+// parseTSV 从 tesseract 的 TSV 输出中取出置信度最高的单词的第一个字符
+func parseTSV(output []byte) (rune, float64, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	if !scanner.Scan() {
+		return 0, 0, errors.New("tesseract produced no TSV header")
+	}
+	header := strings.Split(scanner.Text(), "\t")
+	confColumn, textColumn := indexOf(header, "conf"), indexOf(header, "text")
+	if confColumn < 0 || textColumn < 0 {
+		return 0, 0, fmt.Errorf("unexpected tesseract TSV header %q", scanner.Text())
+	}
+	var best rune
+	bestConf := -1.0
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) <= max(confColumn, textColumn) {
+			continue
+		}
+		text := strings.TrimSpace(fields[textColumn])
+		conf, err := strconv.ParseFloat(fields[confColumn], 64)
+		if text == "" || err != nil || conf <= bestConf {
+			continue
+		}
+		best, _ = utf8.DecodeRuneInString(text)
+		bestConf = conf
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, err
+	}
+	if best == 0 || best == utf8.RuneError {
+		return 0, 0, nil
+	}
+	return best, min(max(bestConf/100, 0), 1), nil
+}
+
+func indexOf(fields []string, name string) int {
+	for i, field := range fields {
+		if field == name {
+			return i
+		}
+	}
+	return -1
+}
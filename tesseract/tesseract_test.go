@@ -0,0 +1,61 @@
+package tesseract
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRecognizer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake tesseract is a shell script")
+	}
+	// 假的 tesseract 记录参数和收到的图像，输出固定的 TSV
+	dir := t.TempDir()
+	script := `#!/bin/sh
+echo "$@" > "$0.args"
+cat > "$0.png"
+printf 'level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n'
+printf '1\t1\t0\t0\t0\t0\t0\t0\t64\t64\t-1\t\n'
+printf '5\t1\t1\t1\t1\t1\t8\t8\t48\t48\t62.5\t口\n'
+printf '5\t1\t1\t1\t1\t2\t8\t8\t48\t48\t87.25\t中\n'
+`
+	path := filepath.Join(dir, "tesseract")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	recognizer := &Recognizer{Path: path, Languages: "chi_sim", Args: []string{"--oem", "1"}}
+	r, confidence, err := recognizer.Recognize(image.NewGray(image.Rect(0, 0, 64, 64)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r != '中' || confidence != 0.8725 {
+		t.Errorf("got %q, %v, want '中', 0.8725", r, confidence)
+	}
+	args, err := os.ReadFile(path + ".args")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.TrimSpace(string(args)), "stdin stdout --psm 10 -l chi_sim --oem 1 tsv"; got != want {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+	if png, err := os.ReadFile(path + ".png"); err != nil || !strings.HasPrefix(string(png), "\x89PNG") {
+		t.Errorf("tesseract did not receive a PNG image: %v", err)
+	}
+
+	if _, _, err := (&Recognizer{Path: filepath.Join(dir, "missing")}).Recognize(image.NewGray(image.Rect(0, 0, 8, 8))); err == nil {
+		t.Error("missing tesseract binary should return an error")
+	}
+}
+
+func TestParseTSV_Empty(t *testing.T) {
+	output := "level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n" +
+		"1\t1\t0\t0\t0\t0\t0\t0\t64\t64\t-1\t\n"
+	if r, confidence, err := parseTSV([]byte(output)); r != 0 || confidence != 0 || err != nil {
+		t.Errorf("got %q, %v, %v, want no result", r, confidence, err)
+	}
+}
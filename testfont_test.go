@@ -0,0 +1,305 @@
+package mapper
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"math"
+	"sort"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/math/fixed"
+)
+
+// testPoint 是测试字形中的一个轮廓点，off 表示曲线控制点
+type testPoint struct {
+	x, y int
+	off  bool
+}
+
+// testComponent 是复合字形中引用的一个子字形
+type testComponent struct {
+	glyph  int
+	dx, dy int
+	scale  float64 // 不为 0 时按 WE_HAVE_A_SCALE 等比缩放子字形
+}
+
+// testGlyph 描述测试字体中的一个字形，raw 非空时直接作为 glyf 数据写入
+type testGlyph struct {
+	contours   [][]testPoint
+	components []testComponent
+	advance    int
+	raw        []byte
+}
+
+// square 返回以 (x, y) 为左下角、边长为 size 的正方形轮廓
+func square(x, y, size int) []testPoint {
+	return []testPoint{{x, y, false}, {x, y + size, false}, {x + size, y + size, false}, {x + size, y, false}}
+}
+
+// triangle 返回一个三角形轮廓
+func triangle(x, y, size int) []testPoint {
+	return []testPoint{{x, y, false}, {x + size/2, y + size, false}, {x + size, y, false}}
+}
+
+// buildTestFont 按给定的字形和 cmap 生成一个最小的 TrueType 字体，
+// 第 0 号字形固定为空的 .notdef，glyphs[i] 对应第 i+1 号字形
+func buildTestFont(upem int, glyphs []testGlyph, cmap map[rune]rune) []byte {
+	all := append([]testGlyph{{advance: upem / 2}}, glyphs...)
+
+	var glyf []byte
+	loca := make([]byte, 0, 4*(len(all)+1))
+	hmtx := make([]byte, 0, 4*len(all))
+	for _, g := range all {
+		loca = binary.BigEndian.AppendUint32(loca, uint32(len(glyf)))
+		data := g.raw
+		if data == nil {
+			data = encodeTestGlyph(g, all)
+		}
+		glyf = append(glyf, data...)
+		for len(glyf)%4 != 0 {
+			glyf = append(glyf, 0)
+		}
+		hmtx = binary.BigEndian.AppendUint16(hmtx, uint16(g.advance))
+		hmtx = binary.BigEndian.AppendUint16(hmtx, uint16(int16(testGlyphXMin(g, all))))
+	}
+	loca = binary.BigEndian.AppendUint32(loca, uint32(len(glyf)))
+
+	head := make([]byte, 54)
+	binary.BigEndian.PutUint32(head[0:], 0x00010000)
+	binary.BigEndian.PutUint32(head[12:], 0x5F0F3CF5)
+	binary.BigEndian.PutUint16(head[18:], uint16(upem))
+	binary.BigEndian.PutUint16(head[40:], uint16(upem))
+	binary.BigEndian.PutUint16(head[42:], uint16(upem))
+	binary.BigEndian.PutUint16(head[50:], 1)
+
+	hhea := make([]byte, 36)
+	binary.BigEndian.PutUint32(hhea[0:], 0x00010000)
+	binary.BigEndian.PutUint16(hhea[4:], uint16(upem))
+	binary.BigEndian.PutUint16(hhea[34:], uint16(len(all)))
+
+	maxp := make([]byte, 32)
+	binary.BigEndian.PutUint32(maxp[0:], 0x00010000)
+	binary.BigEndian.PutUint16(maxp[4:], uint16(len(all)))
+
+	tables := map[string][]byte{
+		"cmap": encodeTestCmap(cmap),
+		"glyf": glyf,
+		"head": head,
+		"hhea": hhea,
+		"hmtx": hmtx,
+		"loca": loca,
+		"maxp": maxp,
+	}
+	return encodeTestSfnt(tables)
+}
+
+func testGlyphXMin(g testGlyph, all []testGlyph) int {
+	xMin, _, _, _, ok := testGlyphBounds(g, all)
+	if !ok {
+		return 0
+	}
+	return xMin
+}
+
+func testGlyphBounds(g testGlyph, all []testGlyph) (xMin, yMin, xMax, yMax int, ok bool) {
+	visit := func(x, y int) {
+		if !ok {
+			xMin, yMin, xMax, yMax, ok = x, y, x, y, true
+			return
+		}
+		xMin, yMin = min(xMin, x), min(yMin, y)
+		xMax, yMax = max(xMax, x), max(yMax, y)
+	}
+	for _, c := range g.contours {
+		for _, p := range c {
+			visit(p.x, p.y)
+		}
+	}
+	for _, c := range g.components {
+		if c.glyph < len(all) {
+			x0, y0, x1, y1, cok := testGlyphBounds(all[c.glyph], all)
+			if cok {
+				if c.scale != 0 {
+					x0, y0 = int(math.Floor(float64(x0)*c.scale)), int(math.Floor(float64(y0)*c.scale))
+					x1, y1 = int(math.Ceil(float64(x1)*c.scale)), int(math.Ceil(float64(y1)*c.scale))
+				}
+				visit(x0+c.dx, y0+c.dy)
+				visit(x1+c.dx, y1+c.dy)
+			}
+		}
+	}
+	return
+}
+
+func encodeTestGlyph(g testGlyph, all []testGlyph) []byte {
+	if len(g.contours) == 0 && len(g.components) == 0 {
+		return nil
+	}
+	var b []byte
+	xMin, yMin, xMax, yMax, _ := testGlyphBounds(g, all)
+	if len(g.components) > 0 {
+		b = binary.BigEndian.AppendUint16(b, 0xFFFF)
+		for _, v := range []int{xMin, yMin, xMax, yMax} {
+			b = binary.BigEndian.AppendUint16(b, uint16(int16(v)))
+		}
+		for i, c := range g.components {
+			const argsAreWords, argsAreXY, haveScale, moreComponents = 0x0001, 0x0002, 0x0008, 0x0020
+			flags := uint16(argsAreWords | argsAreXY)
+			if i < len(g.components)-1 {
+				flags |= moreComponents
+			}
+			if c.scale != 0 {
+				flags |= haveScale
+			}
+			b = binary.BigEndian.AppendUint16(b, flags)
+			b = binary.BigEndian.AppendUint16(b, uint16(c.glyph))
+			b = binary.BigEndian.AppendUint16(b, uint16(int16(c.dx)))
+			b = binary.BigEndian.AppendUint16(b, uint16(int16(c.dy)))
+			if c.scale != 0 {
+				// F2Dot14
+				b = binary.BigEndian.AppendUint16(b, uint16(int16(math.Round(c.scale*(1<<14)))))
+			}
+		}
+		return b
+	}
+
+	b = binary.BigEndian.AppendUint16(b, uint16(len(g.contours)))
+	for _, v := range []int{xMin, yMin, xMax, yMax} {
+		b = binary.BigEndian.AppendUint16(b, uint16(int16(v)))
+	}
+	end := -1
+	for _, c := range g.contours {
+		end += len(c)
+		b = binary.BigEndian.AppendUint16(b, uint16(end))
+	}
+	b = binary.BigEndian.AppendUint16(b, 0)
+	for _, c := range g.contours {
+		for _, p := range c {
+			if p.off {
+				b = append(b, 0)
+			} else {
+				b = append(b, 1)
+			}
+		}
+	}
+	var last int
+	for _, c := range g.contours {
+		for _, p := range c {
+			b = binary.BigEndian.AppendUint16(b, uint16(int16(p.x-last)))
+			last = p.x
+		}
+	}
+	last = 0
+	for _, c := range g.contours {
+		for _, p := range c {
+			b = binary.BigEndian.AppendUint16(b, uint16(int16(p.y-last)))
+			last = p.y
+		}
+	}
+	return b
+}
+
+// encodeTestCmap 生成仅包含 Windows UCS-4 (3, 10) 格式 12 子表的 cmap
+func encodeTestCmap(cmap map[rune]rune) []byte {
+	runes := make([]rune, 0, len(cmap))
+	for r := range cmap {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+
+	var b []byte
+	b = binary.BigEndian.AppendUint16(b, 0)
+	b = binary.BigEndian.AppendUint16(b, 1)
+	b = binary.BigEndian.AppendUint16(b, 3)
+	b = binary.BigEndian.AppendUint16(b, 10)
+	b = binary.BigEndian.AppendUint32(b, 12)
+
+	b = binary.BigEndian.AppendUint16(b, 12)
+	b = binary.BigEndian.AppendUint16(b, 0)
+	b = binary.BigEndian.AppendUint32(b, uint32(16+12*len(runes)))
+	b = binary.BigEndian.AppendUint32(b, 0)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(runes)))
+	for _, r := range runes {
+		b = binary.BigEndian.AppendUint32(b, uint32(r))
+		b = binary.BigEndian.AppendUint32(b, uint32(r))
+		b = binary.BigEndian.AppendUint32(b, uint32(cmap[r]))
+	}
+	return b
+}
+
+func encodeTestSfnt(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	var b []byte
+	b = binary.BigEndian.AppendUint32(b, 0x00010000)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(tags)))
+	b = append(b, make([]byte, 6)...)
+	offset := 12 + 16*len(tags)
+	var body []byte
+	for _, tag := range tags {
+		data := tables[tag]
+		b = append(b, tag...)
+		b = binary.BigEndian.AppendUint32(b, 0)
+		b = binary.BigEndian.AppendUint32(b, uint32(offset+len(body)))
+		b = binary.BigEndian.AppendUint32(b, uint32(len(data)))
+		body = append(body, data...)
+		for len(body)%4 != 0 {
+			body = append(body, 0)
+		}
+	}
+	return append(b, body...)
+}
+
+// toTruetypePoints 把测试轮廓点转换为 truetype.Point，坐标直接使用字体单位
+func toTruetypePoints(points []testPoint) []truetype.Point {
+	result := make([]truetype.Point, len(points))
+	for i, p := range points {
+		result[i] = truetype.Point{X: fixed.Int26_6(p.x), Y: fixed.Int26_6(p.y)}
+		if !p.off {
+			result[i].Flags = 1
+		}
+	}
+	return result
+}
+
+// encodeTestWOFF 把 sfnt 字体封装为 WOFF 1.0，所有表都用 zlib 压缩
+func encodeTestWOFF(sfnt []byte) []byte {
+	n := int(binary.BigEndian.Uint16(sfnt[4:]))
+	header := make([]byte, 44)
+	binary.BigEndian.PutUint32(header[0:], 0x774F4646)
+	copy(header[4:8], sfnt[0:4])
+	binary.BigEndian.PutUint16(header[12:], uint16(n))
+
+	dir := make([]byte, 0, 20*n)
+	var body []byte
+	offset := 44 + 20*n
+	for i := 0; i < n; i++ {
+		entry := sfnt[12+16*i:]
+		start, length := binary.BigEndian.Uint32(entry[8:]), binary.BigEndian.Uint32(entry[12:])
+		var compressed bytes.Buffer
+		w := zlib.NewWriter(&compressed)
+		w.Write(sfnt[start : start+length])
+		w.Close()
+		data := compressed.Bytes()
+		if len(data) >= int(length) {
+			data = sfnt[start : start+length]
+		}
+		dir = append(dir, entry[:4]...)
+		dir = binary.BigEndian.AppendUint32(dir, uint32(offset+len(body)))
+		dir = binary.BigEndian.AppendUint32(dir, uint32(len(data)))
+		dir = binary.BigEndian.AppendUint32(dir, length)
+		dir = append(dir, entry[4:8]...)
+		body = append(body, data...)
+		for len(body)%4 != 0 {
+			body = append(body, 0)
+		}
+	}
+	out := append(append(header, dir...), body...)
+	binary.BigEndian.PutUint32(out[8:], uint32(len(out)))
+	return out
+}
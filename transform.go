@@ -0,0 +1,105 @@
+package mapper
+
+import (
+	"iter"
+	"slices"
+
+	"github.com/golang/freetype/truetype"
+)
+
+// GlyphTransform 是比较之前对特殊字形施加的几何变换，变换都以字形边界框的中心为基准
+type GlyphTransform int
+
+const (
+	// TransformNone 表示不做变换
+	TransformNone GlyphTransform = iota
+	// MirrorHorizontal 左右翻转
+	MirrorHorizontal
+	// MirrorVertical 上下翻转
+	MirrorVertical
+	// Rotate90 逆时针旋转 90°
+	Rotate90
+	// Rotate180 旋转 180°
+	Rotate180
+	// Rotate270 逆时针旋转 270°，即顺时针旋转 90°
+	Rotate270
+)
+
+func (t GlyphTransform) String() string {
+	switch t {
+	case TransformNone:
+		return "none"
+	case MirrorHorizontal:
+		return "mirror horizontal"
+	case MirrorVertical:
+		return "mirror vertical"
+	case Rotate90:
+		return "rotate 90"
+	case Rotate180:
+		return "rotate 180"
+	case Rotate270:
+		return "rotate 270"
+	}
+	return "unknown"
+}
+
+// WithTransforms 在特殊字形本身找不到匹配时，依次尝试施加 transforms 中的变换后再与候选比较，
+// 用于识别翻转或旋转后保存、渲染时再由 OpenType 特性还原的字形。匹配时使用的变换记录在 MappingResult.Transform 中。
+// 变换是对特殊字形的还原：混淆时顺时针旋转了 90° 的字形需要 Rotate90 才能匹配。
+// 每个变换都会让找不到匹配的字符多扫描一遍全部候选
+func WithTransforms(transforms ...GlyphTransform) Option {
+	return func(g *GlyphOutlineMapper) {
+		g.transforms = slices.DeleteFunc(slices.Clone(transforms), func(t GlyphTransform) bool { return t == TransformNone })
+	}
+}
+
+// transformOutline 返回 o 经过变换 t 后的副本，轮廓顺序按变换后的边界重新整理
+func transformOutline(o *outline, t GlyphTransform) *outline {
+	if len(o.points) == 0 {
+		return o
+	}
+	minX, minY, maxX, maxY := o.points[0].X, o.points[0].Y, o.points[0].X, o.points[0].Y
+	for _, p := range o.points[1:] {
+		minX, minY = min(minX, p.X), min(minY, p.Y)
+		maxX, maxY = max(maxX, p.X), max(maxY, p.Y)
+	}
+	sumX, sumY := minX+maxX, minY+maxY // 边界框中心坐标的两倍
+	points := make([]truetype.Point, len(o.points))
+	for i, p := range o.points {
+		x, y := p.X, p.Y
+		switch t {
+		case MirrorHorizontal:
+			x = sumX - p.X
+		case MirrorVertical:
+			y = sumY - p.Y
+		case Rotate90:
+			x, y = (sumX+sumY)/2-p.Y, (sumY-sumX)/2+p.X
+		case Rotate180:
+			x, y = sumX-p.X, sumY-p.Y
+		case Rotate270:
+			x, y = (sumX-sumY)/2+p.Y, (sumX+sumY)/2-p.X
+		}
+		points[i] = truetype.Point{X: x, Y: y, Flags: p.Flags}
+	}
+	transformed := &outline{points: points, ends: slices.Clone(o.ends)}
+	canonicalizeContours(transformed)
+	return transformed
+}
+
+// transformedMatch 依次用 WithTransforms 设置的变换还原特殊字形，并在 candidates 返回的候选中查找匹配，见 pickMatch。
+// special.outline 已经按比较方式规范化过，变换施加在按字形索引 index 重新加载的原始轮廓上，之后再规范化；水平度量沿用 special 的
+func (g *GlyphOutlineMapper) transformedMatch(special *cachedGlyph, index int, candidates func(*cachedGlyph) iter.Seq[*cachedGlyph], detectAmbiguity bool) (MappingResult, bool) {
+	raw, err := g.loadGlyph(g.specialFont, index, g.loadScale(g.specialFont))
+	if err != nil {
+		return MappingResult{}, false
+	}
+	for _, t := range g.transforms {
+		transformed := g.newCachedGlyph(g.specialFont, special.font, special.r, transformOutline(raw, t), 0)
+		transformed.hmetrics = special.hmetrics
+		if result, ok := g.pickMatch(transformed, candidates(transformed), detectAmbiguity); ok {
+			result.Transform = t
+			return result, true
+		}
+	}
+	return MappingResult{}, false
+}
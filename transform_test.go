@@ -0,0 +1,71 @@
+package mapper
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestWithTransforms(t *testing.T) {
+	// 特殊字形是标准字形逐点左右翻转和旋转 180° 之后的结果
+	var mirrored, rotated []testPoint
+	for _, p := range lShape() {
+		mirrored = append(mirrored, testPoint{500 - p.x, p.y, p.off})
+		rotated = append(rotated, testPoint{500 - p.x, 600 - p.y, p.off})
+	}
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{mirrored}, advance: 800},
+		{contours: [][]testPoint{rotated}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{lShape()}, advance: 800},
+	}, map[rune]rune{'A': 1, 'L': 2})
+
+	plain, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := plain.Mapping(0xE000, 0xE001); len(got) != 0 {
+		t.Errorf("transformed glyphs matched without WithTransforms: %v", got)
+	}
+
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithTransforms(MirrorHorizontal, Rotate180))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for special, want := range map[rune]GlyphTransform{0xE000: MirrorHorizontal, 0xE001: Rotate180} {
+		result, ok := mapper.MappingRuneResult(special)
+		if !ok || result.Standard != 'L' || result.Transform != want {
+			t.Errorf("%U: got %+v (ok=%v), want L with %v", special, result, ok, want)
+		}
+	}
+
+	// 变换后的字形沿用特殊字形的水平度量，前进宽度不同的候选仍然被 WithAdvanceFilter 排除
+	narrow := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{lShape()}, advance: 600},
+	}, map[rune]rune{'A': 1, 'L': 2})
+	filtered, err := NewGlyphOutlineMapper(special, narrow, WithTransforms(MirrorHorizontal, Rotate180), WithAdvanceFilter(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := filtered.Mapping(0xE000, 0xE001); len(got) != 0 {
+		t.Errorf("transformed glyphs bypassed the advance filter: %v", got)
+	}
+}
+
+func TestTransformOutline(t *testing.T) {
+	o := &outline{ends: []int{6}}
+	for _, p := range lShape() {
+		o.points = append(o.points, truetype.Point{X: fixed.I(p.x), Y: fixed.I(p.y), Flags: 1})
+	}
+	for _, pair := range [][2]GlyphTransform{{MirrorHorizontal, MirrorHorizontal}, {MirrorVertical, MirrorVertical}, {Rotate90, Rotate270}, {Rotate180, Rotate180}} {
+		got := transformOutline(transformOutline(o, pair[0]), pair[1])
+		if !reflect.DeepEqual(got, o) {
+			t.Errorf("%v then %v: got %v, want %v", pair[0], pair[1], got.points, o.points)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package mapper
+
+import "slices"
+
+// UnmappedReason 是特殊字符没有映射结果的原因
+type UnmappedReason int
+
+const (
+	// UnmappedNoGlyph 表示字符在特殊字体的 cmap 中，但没有可见的字形
+	UnmappedNoGlyph UnmappedReason = iota + 1
+	// UnmappedLoadError 表示特殊字体中的字形损坏，无法加载
+	UnmappedLoadError
+	// UnmappedNoMatch 表示没有标准字符在容差之内
+	UnmappedNoMatch
+	// UnmappedTimeout 表示比较超过了 WithDeadline 设置的时间
+	UnmappedTimeout
+)
+
+func (r UnmappedReason) String() string {
+	switch r {
+	case UnmappedNoGlyph:
+		return "no glyph"
+	case UnmappedLoadError:
+		return "load error"
+	case UnmappedNoMatch:
+		return "no match"
+	case UnmappedTimeout:
+		return "timeout"
+	}
+	return "unknown"
+}
+
+// UnmappedRune 是批量映射中实际检查过、但没有映射结果的特殊字符
+type UnmappedRune struct {
+	Rune   rune
+	Reason UnmappedReason
+	Err    error // ErrNoGlyph、ErrNoMatch、*GlyphLoadError 或包装 ErrCompareTimeout 的 *RuneError
+}
+
+// Unmapped 返回最近一次完成的批量映射中，特殊字体 cmap 里有、但没有映射结果的字符及原因，按码位升序排列。
+// 不在 cmap 中的码位、被忽略的字符以及 ctx 取消后没有比较完的字符不会出现在这里
+func (g *GlyphOutlineMapper) Unmapped() []UnmappedRune {
+	g.lastMu.Lock()
+	defer g.lastMu.Unlock()
+	return slices.Clone(g.unmapped)
+}
+
+// unmappedReason 根据 mappingRune 返回的错误和是否超时判断没有结果的原因
+func (g *GlyphOutlineMapper) unmappedReason(r rune, errs []*GlyphLoadError, timedOut bool) UnmappedRune {
+	if timedOut {
+		return UnmappedRune{Rune: r, Reason: UnmappedTimeout, Err: &RuneError{Rune: r, Font: "special", Err: ErrCompareTimeout}}
+	}
+	for _, err := range errs {
+		if err.Font != "special" {
+			continue
+		}
+		return UnmappedRune{Rune: r, Reason: UnmappedLoadError, Err: err}
+	}
+	if has, _ := g.specialFont.Has(r); !has {
+		return UnmappedRune{Rune: r, Reason: UnmappedNoGlyph, Err: ErrNoGlyph}
+	}
+	return UnmappedRune{Rune: r, Reason: UnmappedNoMatch, Err: ErrNoMatch}
+}
@@ -0,0 +1,42 @@
+package mapper
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGlyphOutlineMapper_Unmapped(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{raw: truncatedGlyph(), advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+		{},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2, 0xE002: 3, 0xE003: 4})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mapper.Mapping(0xE000, 0xE00F)
+	unmapped := mapper.Unmapped()
+	var got []UnmappedReason
+	for _, u := range unmapped {
+		got = append(got, u.Reason)
+	}
+	if want := []UnmappedReason{UnmappedLoadError, UnmappedNoMatch, UnmappedNoGlyph}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", unmapped, want)
+	}
+	if unmapped[0].Rune != 0xE001 || !errors.Is(unmapped[0].Err, ErrGlyphLoad) {
+		t.Errorf("unexpected load error entry: %+v", unmapped[0])
+	}
+	if unmapped[1].Rune != 0xE002 || unmapped[1].Err != ErrNoMatch {
+		t.Errorf("unexpected no match entry: %+v", unmapped[1])
+	}
+	if unmapped[2].Rune != 0xE003 || unmapped[2].Err != ErrNoGlyph {
+		t.Errorf("unexpected no glyph entry: %+v", unmapped[2])
+	}
+}
@@ -0,0 +1,100 @@
+package mapper
+
+import (
+	"iter"
+	"maps"
+	"slices"
+)
+
+// ViolationReason 是 ValidateMapping 认为一条映射不再成立的原因
+type ViolationReason int
+
+const (
+	// ViolationSpecialMissing 表示特殊字体中没有这个字符的字形
+	ViolationSpecialMissing ViolationReason = iota + 1
+	// ViolationStandardMissing 表示所有标准字体中都没有映射到的字符
+	ViolationStandardMissing
+	// ViolationLoadError 表示特殊字形或标准字形损坏，无法加载，原因记录在 Violation.Err 中
+	ViolationLoadError
+	// ViolationMismatch 表示两个字形按配置的比较方式（包括 WithTransforms 的变换）不再一致
+	ViolationMismatch
+	// ViolationUnverified 表示两个字形按轮廓不一致，但 mapper 开启了 WithNearestFallback 或 WithOCRFallback，
+	// 这条映射可能本来就不是按轮廓得到的，无法确认是否仍然成立，需要人工复核
+	ViolationUnverified
+)
+
+func (r ViolationReason) String() string {
+	switch r {
+	case ViolationSpecialMissing:
+		return "special glyph missing"
+	case ViolationStandardMissing:
+		return "standard glyph missing"
+	case ViolationLoadError:
+		return "load error"
+	case ViolationMismatch:
+		return "outline mismatch"
+	case ViolationUnverified:
+		return "not an outline match"
+	}
+	return "unknown"
+}
+
+// Violation 是 ValidateMapping 发现的一条不再成立的映射
+type Violation struct {
+	Special  rune
+	Standard rune
+	Reason   ViolationReason
+	Score    float64 // 两个字形当前的相似度得分，字形不存在或加载失败时为 0
+	Err      error   // 字形加载失败时的错误
+}
+
+// ValidateMapping 用当前的字体重新比较 m 中的每一对字符，返回不再匹配的条目，按特殊字符升序排列。
+// 适合在使用缓存的映射之前检查网站是否悄悄更换了混淆字体。
+// 开启了 WithTransforms 时，特殊字形经过其中一个变换后一致的映射同样成立
+func (g *GlyphOutlineMapper) ValidateMapping(m Mapping) []Violation {
+	var violations []Violation
+	for _, special := range slices.Sorted(maps.Keys(m)) {
+		standard := m[special]
+		violation := Violation{Special: special, Standard: standard}
+		if has, err := g.specialFont.Has(special); err != nil || !has {
+			violation.Reason, violation.Err = ViolationSpecialMissing, err
+			violations = append(violations, violation)
+			continue
+		}
+		i := slices.IndexFunc(g.standardFonts(), func(f namedFont) bool { return f.source.Index(standard) != 0 })
+		if i < 0 {
+			violation.Reason = ViolationStandardMissing
+			violations = append(violations, violation)
+			continue
+		}
+		specialGlyph, err := g.loadCachedGlyph(g.specialFont, "special", special)
+		if err != nil {
+			violation.Reason, violation.Err = ViolationLoadError, err
+			violations = append(violations, violation)
+			continue
+		}
+		f := g.standardFonts()[i]
+		standardGlyph, err := g.loadCachedGlyph(f.source, f.name, standard)
+		if err != nil {
+			violation.Reason, violation.Err = ViolationLoadError, err
+			violations = append(violations, violation)
+			continue
+		}
+		if matched, _, _ := g.matchGlyphs(specialGlyph, standardGlyph); matched {
+			continue
+		}
+		if len(g.transforms) > 0 {
+			only := func(*cachedGlyph) iter.Seq[*cachedGlyph] { return slices.Values([]*cachedGlyph{standardGlyph}) }
+			if _, matched := g.transformedMatch(specialGlyph, g.specialFont.Index(special), only, false); matched {
+				continue
+			}
+		}
+		violation.Reason = ViolationMismatch
+		if g.nearestFallback || g.recognizer != nil {
+			violation.Reason = ViolationUnverified
+		}
+		violation.Score = similarityScore(g.candidateDeviation(specialGlyph, standardGlyph))
+		violations = append(violations, violation)
+	}
+	return violations
+}
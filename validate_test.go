@@ -0,0 +1,75 @@
+package mapper
+
+import "testing"
+
+func TestGlyphOutlineMapper_ValidateMapping(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+	}, map[rune]rune{0xE000: 1, 0xE001: 2})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 400)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+	mapper, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	violations := mapper.ValidateMapping(Mapping{0xE000: 'A', 0xE001: 'C', 0xE002: 'A'})
+	want := []struct {
+		special rune
+		reason  ViolationReason
+	}{
+		{0xE001, ViolationStandardMissing},
+		{0xE002, ViolationSpecialMissing},
+	}
+	if len(violations) != len(want) {
+		t.Fatalf("got %+v, want %d violations", violations, len(want))
+	}
+	for i, w := range want {
+		if violations[i].Special != w.special || violations[i].Reason != w.reason {
+			t.Errorf("violation %d = %+v, want %U %v", i, violations[i], w.special, w.reason)
+		}
+	}
+
+	if violations := mapper.ValidateMapping(Mapping{0xE000: 'B'}); len(violations) != 1 || violations[0].Reason != ViolationMismatch || violations[0].Score <= 0 {
+		t.Errorf("got %+v, want an outline mismatch with a score", violations)
+	}
+	if got := ViolationMismatch.String(); got != "outline mismatch" {
+		t.Errorf("String() = %q", got)
+	}
+
+	// 最接近的候选不是按轮廓得到的映射，只能标记为需要复核
+	nearest, err := NewGlyphOutlineMapper(special, standard, WithNearestFallback())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if violations := nearest.ValidateMapping(Mapping{0xE000: 'B'}); len(violations) != 1 || violations[0].Reason != ViolationUnverified {
+		t.Errorf("got %+v, want the pair labelled as unverified", violations)
+	}
+}
+
+func TestGlyphOutlineMapper_ValidateMappingTransforms(t *testing.T) {
+	var mirrored []testPoint
+	for _, p := range lShape() {
+		mirrored = append(mirrored, testPoint{500 - p.x, p.y, p.off})
+	}
+	special := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{mirrored}, advance: 800}}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{{contours: [][]testPoint{lShape()}, advance: 800}}, map[rune]rune{'L': 1})
+
+	plain, err := NewGlyphOutlineMapper(special, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if violations := plain.ValidateMapping(Mapping{0xE000: 'L'}); len(violations) != 1 || violations[0].Reason != ViolationMismatch {
+		t.Errorf("got %+v, want the mirrored glyph to mismatch without WithTransforms", violations)
+	}
+	mapper, err := NewGlyphOutlineMapper(special, standard, WithTransforms(MirrorHorizontal))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if violations := mapper.ValidateMapping(Mapping{0xE000: 'L'}); len(violations) != 0 {
+		t.Errorf("got %+v, want the mapping found through the transform to hold", violations)
+	}
+}
@@ -0,0 +1,18 @@
+package mapper
+
+import "testing"
+
+func TestXXHash64(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		want  uint64
+	}{
+		{"", 0xef46db3751d8e999},
+		{"abc", 0x44bc2cf5ad770999},
+		{"Nobody inspects the spammish repetition", 0xfbcea83c8a378bf1},
+	} {
+		if got := xxhash64([]byte(tc.input)); got != tc.want {
+			t.Errorf("xxhash64(%q) = %#x, want %#x", tc.input, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package mapper
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+)
+
+// NewGlyphOutlineMapperFromZip 从 zip 压缩包中读取名为 specialEntry 和 standardEntry 的两个字体文件，
+// 字体可以是 TTF、WOFF 或 gzip 压缩后的数据
+func NewGlyphOutlineMapperFromZip(zipPath string, specialEntry, standardEntry string, opts ...Option) (*GlyphOutlineMapper, error) {
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("open zip %s failed: %w", zipPath, err)
+	}
+	defer r.Close()
+	return newGlyphOutlineMapperFromZip(&r.Reader, specialEntry, standardEntry, opts)
+}
+
+// NewGlyphOutlineMapperFromZipReader 与 NewGlyphOutlineMapperFromZip 相同，但读取内存中的 zip 数据
+func NewGlyphOutlineMapperFromZipReader(r io.ReaderAt, size int64, specialEntry, standardEntry string, opts ...Option) (*GlyphOutlineMapper, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("open zip failed: %w", err)
+	}
+	return newGlyphOutlineMapperFromZip(zr, specialEntry, standardEntry, opts)
+}
+
+func newGlyphOutlineMapperFromZip(zr *zip.Reader, specialEntry, standardEntry string, opts []Option) (*GlyphOutlineMapper, error) {
+	specialFontData, err := readZipEntry(zr, specialEntry)
+	if err != nil {
+		return nil, fmt.Errorf("read special font failed: %w", err)
+	}
+	standardFontData, err := readZipEntry(zr, standardEntry)
+	if err != nil {
+		return nil, fmt.Errorf("read standard font failed: %w", err)
+	}
+	return NewGlyphOutlineMapper(specialFontData, standardFontData, opts...)
+}
+
+// readZipEntry 读取 zip 中的一个文件，文件不存在时返回的错误满足 errors.Is(err, fs.ErrNotExist)，
+// 解压后超过 maxFontSize 时返回 ErrFontTooLarge
+func readZipEntry(zr *zip.Reader, name string) ([]byte, error) {
+	f, err := zr.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readFontData(f)
+}
@@ -0,0 +1,89 @@
+package mapper
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/fs"
+	"testing"
+)
+
+func TestNewGlyphOutlineMapperFromZipReader(t *testing.T) {
+	special := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	standard := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{triangle(100, 0, 600)}, advance: 800},
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{'A': 1, 'B': 2})
+
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	gw.Write(standard)
+	gw.Close()
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for name, data := range map[string][]byte{"fonts/special.woff": encodeTestWOFF(special), "fonts/standard.ttf.gz": gzipped.Bytes()} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(data)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r := bytes.NewReader(archive.Bytes())
+
+	mapper, err := NewGlyphOutlineMapperFromZipReader(r, r.Size(), "fonts/special.woff", "fonts/standard.ttf.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, standardRune, ok := mapper.MappingRune(0xE000); !ok || standardRune != 'B' {
+		t.Fatalf("got %q (ok=%v), want 'B'", standardRune, ok)
+	}
+
+	_, err = NewGlyphOutlineMapperFromZipReader(r, r.Size(), "fonts/special.woff", "missing.ttf")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing entry error = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestDecodeFontData_SizeLimit(t *testing.T) {
+	defer func(size int64) { maxFontSize = size }(maxFontSize)
+	maxFontSize = 1 << 10
+
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	gw.Write(make([]byte, 1<<20))
+	gw.Close()
+	if _, err := decodeFontData(gzipped.Bytes()); !errors.Is(err, ErrFontTooLarge) {
+		t.Errorf("gzip error = %v, want ErrFontTooLarge", err)
+	}
+
+	font := buildTestFont(1000, []testGlyph{
+		{contours: [][]testPoint{square(100, 100, 500)}, advance: 800},
+	}, map[rune]rune{0xE000: 1})
+	maxFontSize = int64(len(font)) / 2
+	if _, err := decodeFontData(encodeTestWOFF(font)); !errors.Is(err, ErrFontTooLarge) {
+		t.Errorf("WOFF error = %v, want ErrFontTooLarge", err)
+	}
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	w, err := zw.Create("font.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(font)
+	zw.Close()
+	zr, err := zip.NewReader(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readZipEntry(zr, "font.ttf"); !errors.Is(err, ErrFontTooLarge) {
+		t.Errorf("zip error = %v, want ErrFontTooLarge", err)
+	}
+}